@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/LJ-Software/gdbuf/internal/codegen"
+)
+
+// protoc-gen-gdbuf is a protoc plugin that generates the gdbuf Godot wrapper
+// sources from a CodeGeneratorRequest read on stdin. The nanopb sources are
+// not produced by this plugin, run protoc-gen-nanopb next to it with the
+// "-s type:FT_POINTER" option into the src directory of the same output.
+func main() {
+	// stdout is reserved for the CodeGeneratorResponse
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	if err := run(logger, os.Stdin, os.Stdout); err != nil {
+		logger.Error("protoc-gen-gdbuf failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger *slog.Logger, in io.Reader, out io.Writer) error {
+	requestData, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("could not read code generator request: %w", err)
+	}
+
+	var request pluginpb.CodeGeneratorRequest
+	if err := proto.Unmarshal(requestData, &request); err != nil {
+		return fmt.Errorf("could not unmarshal code generator request: %w", err)
+	}
+
+	response := generate(logger, &request)
+
+	responseData, err := proto.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("could not marshal code generator response: %w", err)
+	}
+
+	if _, err := out.Write(responseData); err != nil {
+		return fmt.Errorf("could not write code generator response: %w", err)
+	}
+	return nil
+}
+
+// generate runs the code generator for the request. Problems with the protos
+// themselves are reported through the response error, as protoc expects.
+func generate(logger *slog.Logger, request *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
+	response := &pluginpb.CodeGeneratorResponse{
+		SupportedFeatures: proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)),
+	}
+
+	extensionName := "gdbufgen"
+	var options codegen.Options
+	for param := range strings.SplitSeq(request.GetParameter(), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch key {
+		case "":
+		case "name":
+			extensionName = value
+		case "enum_scope":
+			options.EnumScope = codegen.EnumScope(value)
+		case "uint64_policy":
+			options.Uint64Policy = codegen.Uint64Policy(value)
+		case "time_type":
+			options.TimeType = codegen.TimeType(value)
+		case "class_naming":
+			options.ClassNaming = codegen.ClassNaming(value)
+		case "class_names":
+			classNames, err := codegen.LoadClassNames(value)
+			if err != nil {
+				response.Error = proto.String(err.Error())
+				return response
+			}
+			options.ClassNames = classNames
+		default:
+			response.Error = proto.String(fmt.Sprintf("unknown parameter: %s", key))
+			return response
+		}
+	}
+
+	genOutDir, err := os.MkdirTemp("", "gdbuf-plugin-")
+	if err != nil {
+		response.Error = proto.String(fmt.Sprintf("could not create temp dir for generated code: %v", err))
+		return response
+	}
+	defer os.RemoveAll(genOutDir)
+
+	codeGenerator, err := codegen.NewCodeGenerator(logger, genOutDir, extensionName, compilerVersion(request.GetCompilerVersion()), options)
+	if err != nil {
+		response.Error = proto.String(fmt.Sprintf("could not create new code generator: %v", err))
+		return response
+	}
+
+	if err := codeGenerator.GenerateCode(filesToGenerate(request)); err != nil {
+		response.Error = proto.String(fmt.Sprintf("problem generating code: %v", err))
+		return response
+	}
+
+	err = filepath.WalkDir(genOutDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(genOutDir, path)
+		if err != nil {
+			return err
+		}
+		response.File = append(response.File, &pluginpb.CodeGeneratorResponse_File{
+			Name:    proto.String(filepath.ToSlash(relPath)),
+			Content: proto.String(string(content)),
+		})
+		return nil
+	})
+	if err != nil {
+		response.Error = proto.String(fmt.Sprintf("could not collect generated files: %v", err))
+		return response
+	}
+
+	return response
+}
+
+// filesToGenerate returns the requested files along with every file they
+// import. The generated extension has to contain the wrapper classes of
+// imported messages too, otherwise the generated includes would not resolve.
+func filesToGenerate(request *pluginpb.CodeGeneratorRequest) []*descriptorpb.FileDescriptorProto {
+	filesByName := make(map[string]*descriptorpb.FileDescriptorProto)
+	for _, file := range request.GetProtoFile() {
+		filesByName[file.GetName()] = file
+	}
+
+	var wanted []string
+	var visit func(name string)
+	visit = func(name string) {
+		if slices.Contains(wanted, name) {
+			return
+		}
+		file, ok := filesByName[name]
+		if !ok {
+			return
+		}
+		wanted = append(wanted, name)
+		for _, dep := range file.GetDependency() {
+			visit(dep)
+		}
+	}
+	for _, name := range request.GetFileToGenerate() {
+		visit(name)
+	}
+
+	// keep the order protoc gave us, it is topologically sorted
+	var files []*descriptorpb.FileDescriptorProto
+	for _, file := range request.GetProtoFile() {
+		if slices.Contains(wanted, file.GetName()) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func compilerVersion(version *pluginpb.Version) string {
+	if version == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", version.GetMajor(), version.GetMinor(), version.GetPatch())
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestGenerate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	request := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"game/player.proto"},
+		Parameter:      proto.String("name=game-protos"),
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("google/protobuf/timestamp.proto"),
+				Package: proto.String("google.protobuf"),
+				MessageType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("Timestamp")},
+				},
+			},
+			{
+				Name:    proto.String("game/item.proto"),
+				Package: proto.String("game"),
+				MessageType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("Item")},
+				},
+			},
+			{
+				Name:    proto.String("game/unused.proto"),
+				Package: proto.String("game"),
+			},
+			{
+				Name:       proto.String("game/player.proto"),
+				Package:    proto.String("game"),
+				Dependency: []string{"game/item.proto", "google/protobuf/timestamp.proto"},
+				MessageType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("Player"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{
+								Name:     proto.String("item"),
+								Number:   proto.Int32(1),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+								TypeName: proto.String(".game.Item"),
+							},
+							{
+								Name:     proto.String("joined_at"),
+								Number:   proto.Int32(2),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+								TypeName: proto.String(".google.protobuf.Timestamp"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	response := generate(logger, request)
+	if response.Error != nil {
+		t.Fatalf("generate() error = %s", response.GetError())
+	}
+
+	var names []string
+	var typeRegistry string
+	for _, file := range response.GetFile() {
+		names = append(names, file.GetName())
+		if file.GetName() == "src/type_registry.cpp" {
+			typeRegistry = file.GetContent()
+		}
+	}
+
+	for _, want := range []string{
+		"CMakeLists.txt",
+		"src/register_types.cpp",
+		"src/type_registry.cpp",
+		"src/game/player.h",
+		"src/game/player.cpp",
+		"src/game/item.h",
+		"doc_classes/Player.xml",
+		"doc_classes/Item.xml",
+	} {
+		if !slices.Contains(names, want) {
+			t.Errorf("generate() missing file %s, got %v", want, names)
+		}
+	}
+
+	for _, unwanted := range []string{
+		"src/game/unused.h",
+		"src/google/protobuf/timestamp.h",
+		"doc_classes/Timestamp.xml",
+	} {
+		if slices.Contains(names, unwanted) {
+			t.Errorf("generate() produced unexpected file %s", unwanted)
+		}
+	}
+
+	for _, want := range []string{`{"game.Player", "Player",`, `{"game.Item", "Item",`, "game_protosAny::pack_any"} {
+		if !strings.Contains(typeRegistry, want) {
+			t.Errorf("type registry is missing %s", want)
+		}
+	}
+}
+
+func TestGenerateUnknownParameter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	response := generate(logger, &pluginpb.CodeGeneratorRequest{Parameter: proto.String("bogus=1")})
+	if response.Error == nil {
+		t.Errorf("generate() expected error for unknown parameter")
+	}
+}
@@ -1,17 +1,19 @@
 package codegen
 
 import (
+	"cmp"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
-	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -28,19 +30,82 @@ func toPascalCase(s string) string {
 	return strings.Join(parts, "")
 }
 
+// EnumScope selects which generated class the top-level enums of a proto file are registered on.
+type EnumScope string
+
+const (
+	// EnumScopePackage registers top-level enums on one class per proto package, e.g. gdbufgenGameV1Enums.
+	EnumScopePackage EnumScope = "package"
+	// EnumScopeFile registers top-level enums on one class per proto file, e.g. gdbufgenPlayerEnums for player.proto.
+	EnumScopeFile EnumScope = "file"
+)
+
+// ClassNaming selects how the Godot class name of a message is derived from its proto name.
+type ClassNaming string
+
+const (
+	// ClassNamingBare drops the proto package, game.v1.Player becomes Player.
+	ClassNamingBare ClassNaming = "bare"
+	// ClassNamingPackage prefixes the proto package, game.v1.Player becomes GameV1Player.
+	ClassNamingPackage ClassNaming = "package"
+)
+
+// Uint64Policy selects how uint64 and fixed64 fields are exposed, Godot integers are signed 64-bit
+// so values above INT64_MAX do not fit.
+type Uint64Policy string
+
+const (
+	// Uint64Wrap exposes an int, values above INT64_MAX read back negative with the same bits.
+	Uint64Wrap Uint64Policy = "wrap"
+	// Uint64Clamp exposes an int, values above INT64_MAX are clamped with a warning, negative ones encode as 0.
+	Uint64Clamp Uint64Policy = "clamp"
+	// Uint64String exposes the decimal digits as a String.
+	Uint64String Uint64Policy = "string"
+	// Uint64Bytes exposes the 8 little-endian bytes as a PackedByteArray, see PackedByteArray.decode_u64().
+	Uint64Bytes Uint64Policy = "bytes"
+)
+
+// TimeType selects how google.protobuf.Timestamp and google.protobuf.Duration fields are exposed.
+type TimeType string
+
+const (
+	// TimeNative exposes a Timestamp as an int of unix milliseconds and a Duration as a float of seconds.
+	TimeNative TimeType = "native"
+	// TimeResource exposes the ProtoTimestamp and ProtoDuration resources, which keep nanosecond precision.
+	TimeResource TimeType = "resource"
+)
+
+// Options configures how proto names are mapped onto the generated Godot classes.
+type Options struct {
+	EnumScope    EnumScope         // defaults to EnumScopePackage
+	ClassNaming  ClassNaming       // defaults to ClassNamingBare
+	ClassNames   map[string]string // fully qualified message name (without leading dot) to Godot class name, wins over ClassNaming
+	Uint64Policy Uint64Policy      // defaults to Uint64Wrap
+	TimeType     TimeType          // defaults to TimeNative
+
+	// LegacyNestedConversion converts nested messages through a serialized bytes round trip like
+	// older gdbuf versions did, only meant as the baseline of the benchmark
+	LegacyNestedConversion bool
+}
+
+var godotIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 type CodeGenerator struct {
 	logger                   *slog.Logger
 	destinationDirectoryPath string
 	templates                *template.Template
 	extensionName            string
 	protobufVersion          string
+	options                  Options
 }
 
 type templateData struct {
 	GDExtensionName string
 	ProtobufVersion string
 	ProtoData       protoData
-	GlobalEnums     []protoEnum
+	GlobalEnums     []protoEnumsClass
+	TimeResources   bool // register ProtoTimestamp and ProtoDuration
+	LegacyNested    bool // define GDBUF_LEGACY_NESTED_CONVERSION in messages.h
 }
 
 type protoData struct {
@@ -62,12 +127,15 @@ type ForwardDecl struct {
 }
 
 type protoMessage struct {
-	ClassName   string
-	MessageName string
-	Description string
-	Fields      []protoMessageField
-	Oneofs      []protoOneof
-	Enums       []protoEnum
+	ClassName         string
+	MessageName       string
+	FullName          string // fully qualified proto name without the leading dot, e.g. pkg.Outer.Inner
+	Description       string
+	Fields            []protoMessageField
+	Oneofs            []protoOneof
+	Enums             []protoEnum
+	HasRequiredFields bool // the message or any message reachable from it declares required fields
+	HasGroups         bool // declares proto2 group fields, nanopb cannot hand those to the streaming callbacks
 }
 
 type protoOneof struct {
@@ -82,30 +150,106 @@ type protoOneofField struct {
 
 type protoEnum struct {
 	EnumName string
-	Options  []string
+	Values   []protoEnumValue
+}
+
+// protoEnumsClass is a generated class holding the top-level enums of one enum scope.
+type protoEnumsClass struct {
+	ClassName string
+	Scope     string // proto package or proto file the enums are declared in
+	Enums     []protoEnum
+}
+
+type protoEnumValue struct {
+	Name        string
+	Number      int32
+	Description string
 }
 
 type protoMessageField struct {
-	FieldName           string
-	ProtoTypeName       string
-	GodotType           string
-	GodotClassName      string
-	InnerGodotType      string
-	InnerGodotClassName string
-	IsCustomType        bool
-	IsInnerCustomType   bool
-	IsRepeated          bool
-	IsEnum              bool
-	IsMap               bool
-	MapKeyGodotType     string
-	MapValueGodotType   string
-	MapValueIsCustom    bool
-	Description         string
-	OneofName           string
-	Number              int32
-}
-
-func NewCodeGenerator(logger *slog.Logger, destinationDirectoryPath, extensionName, protobufVersion string) (*CodeGenerator, error) {
+	FieldName              string
+	ProtoTypeName          string
+	GodotType              string
+	GodotClassName         string
+	InnerGodotType         string
+	InnerGodotClassName    string
+	IsCustomType           bool
+	IsInnerCustomType      bool
+	IsRepeated             bool
+	IsEnum                 bool
+	IsMap                  bool
+	IsWrapper              bool   // google.protobuf wrapper message, exposed as a nullable Variant
+	IsGroup                bool   // proto2 group, encoded between START_GROUP and END_GROUP tags instead of with a length
+	TextName               string // name in the text format, the message type name for groups, e.g. Settings
+	MapKeyGodotType        string
+	MapValueGodotType      string
+	MapValueGodotClassName string
+	MapValueProtoTypeName  string // proto type name of a message or enum map value, e.g. ".dependency.DependencyMessage"
+	MapValueIsCustom       bool
+	MapValueIsEnum         bool
+	MapValueIsWrapper      bool
+	MapKeyUint64Policy     Uint64Policy
+	MapValueUint64Policy   Uint64Policy
+	Description            string
+	OneofName              string
+	Number                 int32
+	HasPresence            bool
+	IsRequired             bool
+	HasRequiredFields      bool         // the field's message type (or map value type) needs an initialization check
+	DefaultValue           string       // C++ expression of the declared default, empty when there is none
+	EnumHint               string       // PROPERTY_HINT_ENUM hint string, e.g. "RED:0,GREEN:1"
+	EnumClassName          string       // qualified Godot enum name used as typed enum metadata, e.g. "gdbufgenEnums.Color"
+	Uint64Policy           Uint64Policy // set on uint64/fixed64 fields not exposed as a plain int, see GDBufUtils::uint64_to_<policy>
+	ArrayTypeHint          string       // PROPERTY_HINT_ARRAY_TYPE hint string of a typed repeated field, e.g. "int" or "Item"
+	MapTypeHint            string       // PROPERTY_HINT_DICTIONARY_TYPE hint string of a typed map field, e.g. "String;Item"
+	JSONName               string       // lowerCamelCase name used by the proto3 JSON mapping
+	ValueKind              string       // GDBufUtils::ValueKind of the value, or of the elements of a repeated field
+	MapKeyKind             string
+	MapValueKind           string
+	WireType               string // GDBufUtils::WireType suffix of the value, or of the elements of a repeated field, e.g. SINT32
+	MapKeyWireType         string
+	MapValueWireType       string
+	MapValueEnumHint       string
+	VariantType            string // godot::Variant::Type of the value, or of the elements of a repeated field, wrappers resolve to the type they wrap
+	MapKeyVariantType      string
+	MapValueVariantType    string
+}
+
+func NewCodeGenerator(logger *slog.Logger, destinationDirectoryPath, extensionName, protobufVersion string, options Options) (*CodeGenerator, error) {
+	switch options.EnumScope {
+	case "":
+		options.EnumScope = EnumScopePackage
+	case EnumScopePackage, EnumScopeFile:
+	default:
+		return nil, fmt.Errorf("unknown enum scope %q, expected %s or %s", options.EnumScope, EnumScopePackage, EnumScopeFile)
+	}
+	switch options.ClassNaming {
+	case "":
+		options.ClassNaming = ClassNamingBare
+	case ClassNamingBare, ClassNamingPackage:
+	default:
+		return nil, fmt.Errorf("unknown class naming %q, expected %s or %s", options.ClassNaming, ClassNamingBare, ClassNamingPackage)
+	}
+	switch options.Uint64Policy {
+	case "":
+		options.Uint64Policy = Uint64Wrap
+	case Uint64Wrap, Uint64Clamp, Uint64String, Uint64Bytes:
+	default:
+		return nil, fmt.Errorf("unknown uint64 policy %q, expected %s, %s, %s or %s", options.Uint64Policy, Uint64Wrap, Uint64Clamp, Uint64String, Uint64Bytes)
+	}
+	switch options.TimeType {
+	case "":
+		options.TimeType = TimeNative
+	case TimeNative, TimeResource:
+	default:
+		return nil, fmt.Errorf("unknown time type %q, expected %s or %s", options.TimeType, TimeNative, TimeResource)
+	}
+	for fullName, className := range options.ClassNames {
+		if !godotIdentifierRegexp.MatchString(className) {
+			return nil, fmt.Errorf("invalid class name %q for message %s, expected a C++ identifier", className, fullName)
+		}
+	}
+
 	tmpl, err := template.New("gdbuf").Funcs(getTemplateFuncMap()).ParseFS(templatesFS, "templates/**/*.tmpl", "templates/**/**/*.tmpl")
 	if err != nil {
 		return nil, fmt.Errorf("could not parse code generation file templates: %w", err)
@@ -123,6 +267,7 @@ func NewCodeGenerator(logger *slog.Logger, destinationDirectoryPath, extensionNa
 		templates:                tmpl,
 		extensionName:            strings.ReplaceAll(extensionName, "-", "_"),
 		protobufVersion:          protobufVersion,
+		options:                  options,
 	}, nil
 }
 
@@ -136,35 +281,41 @@ func getTemplateFuncMap() template.FuncMap {
 		// Replace dots with underscores
 		return strings.ReplaceAll(s, ".", "_")
 	}
-	f["godotVariantType"] = func(godotType string, isCustom bool, isEnum bool) string {
-		if isEnum {
-			return "godot::Variant::INT"
+	f["godotVariantType"] = godotVariantType
+	f["nanopbOrder"] = func(fields []protoMessageField) []protoMessageField {
+		// nanopb encodes fields by number, the members of a oneof together at the lowest number among them.
+		// The streaming callbacks keep that order so both paths write the same bytes
+		oneofNumbers := map[string]int32{}
+		for _, field := range fields {
+			if number, ok := oneofNumbers[field.OneofName]; field.OneofName != "" && (!ok || field.Number < number) {
+				oneofNumbers[field.OneofName] = field.Number
+			}
 		}
-		if isCustom {
-			return "godot::Variant::OBJECT"
-		}
-		switch godotType {
-		case "bool":
-			return "godot::Variant::BOOL"
-		case "int32_t", "int64_t", "uint32_t", "uint64_t":
-			return "godot::Variant::INT"
-		case "float", "double":
-			return "godot::Variant::FLOAT"
-		case "godot::String":
-			return "godot::Variant::STRING"
-		case "godot::PackedByteArray":
-			return "godot::Variant::PACKED_BYTE_ARRAY"
-		case "godot::PackedStringArray":
-			return "godot::Variant::PACKED_STRING_ARRAY"
-		case "godot::Dictionary":
-			return "godot::Variant::DICTIONARY"
-		case "godot::Array":
-			return "godot::Variant::ARRAY"
-		default:
-			return "godot::Variant::NIL"
+		position := func(field protoMessageField) int32 {
+			if field.OneofName != "" {
+				return oneofNumbers[field.OneofName]
+			}
+			return field.Number
 		}
+		sorted := slices.Clone(fields)
+		slices.SortStableFunc(sorted, func(a, b protoMessageField) int {
+			return cmp.Or(cmp.Compare(position(a), position(b)), cmp.Compare(a.Number, b.Number))
+		})
+		return sorted
 	}
 	f["godotDocType"] = func(godotType string, isCustom bool, isEnum bool) string {
+		// typed arrays use the Type[] notation of the Godot class reference
+		if elementType, ok := strings.CutPrefix(godotType, "godot::TypedArray<"); ok {
+			elementType = strings.TrimSuffix(elementType, ">")
+			if isEnum {
+				return "int[]"
+			}
+			return godotElementDocType(elementType) + "[]"
+		}
+		if elementTypes, ok := strings.CutPrefix(godotType, "godot::TypedDictionary<"); ok {
+			keyType, valueType, _ := strings.Cut(strings.TrimSuffix(elementTypes, ">"), ", ")
+			return "Dictionary[" + godotBuiltinTypeName(keyType) + ", " + godotElementDocType(valueType) + "]"
+		}
 		if isEnum {
 			return "int"
 		}
@@ -172,42 +323,35 @@ func getTemplateFuncMap() template.FuncMap {
 			parts := strings.Split(godotType, "::")
 			return parts[len(parts)-1]
 		}
-		switch godotType {
-		case "bool":
-			return "bool"
-		case "int32_t", "int64_t", "uint32_t", "uint64_t":
-			return "int"
-		case "float", "double":
-			return "float"
-		case "godot::String":
-			return "String"
-		case "godot::PackedByteArray":
-			return "PackedByteArray"
-		case "godot::PackedStringArray":
-			return "PackedStringArray"
-		case "godot::Dictionary":
-			return "Dictionary"
-		case "godot::Array":
-			return "Array"
-		default:
-			return "Variant"
-		}
+		return godotBuiltinTypeName(godotType)
 	}
 	return f
 }
 
 func (cg *CodeGenerator) GenerateCode(fileDescriptorSet []*descriptorpb.FileDescriptorProto) error {
+	// well-known types are mapped onto native godot types, they never get wrapper classes of their own
+	fileDescriptorSet = slices.DeleteFunc(slices.Clone(fileDescriptorSet), func(file *descriptorpb.FileDescriptorProto) bool {
+		return isWellKnownTypeFile(file.GetName())
+	})
+
 	// first extract all of the data needed
 	protoData, err := cg.extractProtoData(fileDescriptorSet)
 	if err != nil {
 		return fmt.Errorf("problem extracting proto data: %w", err)
 	}
 
+	globalEnums, err := cg.extractGlobalEnums(fileDescriptorSet, protoData)
+	if err != nil {
+		return fmt.Errorf("problem extracting global enums: %w", err)
+	}
+
 	templateData := templateData{
 		GDExtensionName: cg.extensionName,
 		ProtobufVersion: cg.protobufVersion,
 		ProtoData:       *protoData,
-		GlobalEnums:     cg.extractGlobalEnums(fileDescriptorSet),
+		GlobalEnums:     globalEnums,
+		TimeResources:   cg.options.TimeType == TimeResource,
+		LegacyNested:    cg.options.LegacyNestedConversion,
 	}
 
 	oneTimeTemplates := map[string]string{
@@ -219,6 +363,10 @@ func (cg *CodeGenerator) GenerateCode(fileDescriptorSet []*descriptorpb.FileDesc
 		"messages.cpp.tmpl":             "src/messages.cpp",
 		"global_enums.h.tmpl":           "src/global_enums.h",
 		"global_enums.cpp.tmpl":         "src/global_enums.cpp",
+		"type_registry.h.tmpl":          "src/type_registry.h",
+		"type_registry.cpp.tmpl":        "src/type_registry.cpp",
+		"time_types.h.tmpl":             "src/time_types.h",
+		"time_types.cpp.tmpl":           "src/time_types.cpp",
 	}
 
 	for templateName, outputPath := range oneTimeTemplates {
@@ -258,28 +406,62 @@ func (cg *CodeGenerator) GenerateCode(fileDescriptorSet []*descriptorpb.FileDesc
 	}
 
 	// Generate documentation for GlobalEnums
-	if len(templateData.GlobalEnums) > 0 {
+	for _, enumsClass := range templateData.GlobalEnums {
 		globalEnumsMsg := protoMessage{
-			ClassName:   cg.extensionName + "Enums",
-			Description: "Contains all top-level enums defined in proto files.",
-			Enums:       templateData.GlobalEnums,
+			ClassName:   enumsClass.ClassName,
+			Description: fmt.Sprintf("Contains the top-level enums defined in %s.", enumsClass.Scope),
+			Enums:       enumsClass.Enums,
 		}
 		outputPath := filepath.Join(cg.destinationDirectoryPath, "doc_classes", globalEnumsMsg.ClassName+".xml")
 		if err := cg.executeTemplate("class_doc.xml.tmpl", outputPath, globalEnumsMsg); err != nil {
-			return fmt.Errorf("could not execute template class_doc.xml.tmpl for global enums: %w", err)
+			return fmt.Errorf("could not execute template class_doc.xml.tmpl for global enums %s: %w", enumsClass.ClassName, err)
+		}
+	}
+
+	if templateData.TimeResources {
+		for _, timeMsg := range timeResourceDocs() {
+			outputPath := filepath.Join(cg.destinationDirectoryPath, "doc_classes", timeMsg.ClassName+".xml")
+			if err := cg.executeTemplate("class_doc.xml.tmpl", outputPath, timeMsg); err != nil {
+				return fmt.Errorf("could not execute template class_doc.xml.tmpl for %s: %w", timeMsg.ClassName, err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// timeResourceDocs describes the members of ProtoTimestamp and ProtoDuration for their class docs.
+func timeResourceDocs() []protoMessage {
+	return []protoMessage{
+		{
+			ClassName:   "ProtoTimestamp",
+			Description: "A google.protobuf.Timestamp with nanosecond precision. Use from_unix_time() and to_unix_time() to convert from and to unix floats, from_datetime_dict() and to_datetime_dict() to convert from and to Time dictionaries.",
+			Fields: []protoMessageField{
+				{FieldName: "seconds", GodotType: "int64_t", Description: "Seconds since the Unix epoch, 1970-01-01T00:00:00Z."},
+				{FieldName: "nanos", GodotType: "int32_t", Description: "Non-negative fractions of a second at nanosecond resolution, values outside 0 to 999999999 carry over into seconds."},
+			},
+		},
+		{
+			ClassName:   "ProtoDuration",
+			Description: "A google.protobuf.Duration with nanosecond precision. Use from_seconds() and to_seconds() to convert from and to floats, from_nanoseconds() and to_nanoseconds() for exact values.",
+			Fields: []protoMessageField{
+				{FieldName: "seconds", GodotType: "int64_t", Description: "Whole seconds of the span of time."},
+				{FieldName: "nanos", GodotType: "int32_t", Description: "Fractions of a second at nanosecond resolution, always with the same sign as seconds."},
+			},
+		},
+	}
+}
+
 func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.FileDescriptorProto) (*protoData, error) {
 	var protoData protoData
 	// one loop through to get a mapping of filename and message name (recursive)
 	var protoFileToDeclaredMessageNames map[string][]string = make(map[string][]string)
 	var protoFileToDeclaredEnumNames map[string][]string = make(map[string][]string)
 	var allMessageDescriptors map[string]*descriptorpb.DescriptorProto = make(map[string]*descriptorpb.DescriptorProto)
+	var allEnumDescriptors map[string]*descriptorpb.EnumDescriptorProto = make(map[string]*descriptorpb.EnumDescriptorProto)
 	var typeToGodotName map[string]string = make(map[string]string)
+	var typeToClassName map[string]string = make(map[string]string)
+	var enumToGodotName map[string]string = make(map[string]string)
 
 	for _, file := range fileDescriptorSet {
 		pkg := file.GetPackage()
@@ -302,13 +484,16 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 				// Replace . with _
 				godotName := strings.ReplaceAll(shortName, ".", "_")
 				typeToGodotName[fullName] = godotName
+				typeToClassName[fullName] = cg.messageClassName(pkg, godotName, fullName)
 
 				traverseMsgs(msg.GetNestedType(), fullName+".")
 
-				// Also traverse Nested Enums
+				// Also traverse Nested Enums, they are registered on the owning message class
 				for _, enum := range msg.GetEnumType() {
 					enumFullName := fullName + "." + enum.GetName()
 					protoFileToDeclaredEnumNames[file.GetName()] = append(protoFileToDeclaredEnumNames[file.GetName()], enumFullName)
+					allEnumDescriptors[enumFullName] = enum
+					enumToGodotName[enumFullName] = typeToClassName[fullName] + "." + enum.GetName()
 				}
 			}
 		}
@@ -318,9 +503,15 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 		for _, enum := range file.GetEnumType() {
 			fullName := prefix + enum.GetName()
 			protoFileToDeclaredEnumNames[file.GetName()] = append(protoFileToDeclaredEnumNames[file.GetName()], fullName)
+			allEnumDescriptors[fullName] = enum
+			enumToGodotName[fullName] = cg.enumsClassName(file) + "." + enum.GetName()
 		}
 	}
 
+	if err := cg.validateClassNames(typeToClassName, allMessageDescriptors); err != nil {
+		return nil, err
+	}
+
 	// now for the real deal
 	for _, file := range fileDescriptorSet {
 		var protoFile protoFile
@@ -334,14 +525,31 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 			prefix = "." + pkg + "."
 		}
 
-		for _, enum := range file.GetEnumType() {
-			var protoEnum protoEnum
-			protoEnum.EnumName = enum.GetName()
-			enum.GetOptions().ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
-				protoEnum.Options = append(protoEnum.Options, fd.TextName())
-				return true
-			})
-			protoFile.Enums = append(protoFile.Enums, protoEnum)
+		for enumIndex, enum := range file.GetEnumType() {
+			// EnumType is field 5 in FileDescriptorProto
+			protoFile.Enums = append(protoFile.Enums, newProtoEnum(file, enum, []int32{5, int32(enumIndex)}))
+		}
+
+		// addDependency includes the header of a message class generated for another proto file
+		addDependency := func(godotType, srcFile string) {
+			if srcFile == "" || srcFile == "google::protobuf" || srcFile == protoFile.ProtoPath {
+				return
+			}
+			headerPath := strings.TrimSuffix(srcFile, ".proto") + ".h"
+			if !slices.Contains(protoFile.Dependencies, headerPath) {
+				protoFile.Dependencies = append(protoFile.Dependencies, headerPath)
+			}
+
+			// Add forward declaration
+			parts := strings.Split(godotType, "::")
+			if len(parts) > 1 {
+				className := parts[len(parts)-1]
+				namespace := strings.Join(parts[:len(parts)-1], "::")
+				fd := ForwardDecl{Namespace: namespace, ClassName: className}
+				if !slices.Contains(protoFile.ForwardDecls, fd) {
+					protoFile.ForwardDecls = append(protoFile.ForwardDecls, fd)
+				}
+			}
 		}
 
 		// Recursive generation
@@ -358,10 +566,17 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 
 				var protoMessage protoMessage
 				protoMessage.MessageName = godotName
-				protoMessage.ClassName = toPascalCase(godotName)
+				protoMessage.ClassName = typeToClassName[fullName]
+				protoMessage.FullName = strings.TrimPrefix(fullName, ".")
+				protoMessage.HasRequiredFields = messageHasRequiredFields(fullName, allMessageDescriptors, map[string]bool{})
 				currentPath := append(slices.Clone(path), int32(msgIndex))
 				protoMessage.Description = getComments(file.GetSourceCodeInfo(), currentPath)
 
+				// EnumType is field 4 in DescriptorProto
+				for enumIndex, enum := range msg.GetEnumType() {
+					protoMessage.Enums = append(protoMessage.Enums, newProtoEnum(file, enum, append(slices.Clone(currentPath), 4, int32(enumIndex))))
+				}
+
 				// Process Oneofs
 				oneofDecls := msg.GetOneofDecl()
 				for i, oneof := range oneofDecls {
@@ -381,6 +596,16 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 					protoMessageField.FieldName = field.GetName()
 					protoMessageField.ProtoTypeName = field.GetTypeName()
 					protoMessageField.Number = field.GetNumber()
+					protoMessageField.HasPresence = fieldHasPresence(file, field)
+					protoMessageField.IsRequired = fieldIsRequired(field)
+					protoMessageField.IsGroup = field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP
+					protoMessageField.TextName = textName(field)
+					protoMessageField.HasRequiredFields = messageHasRequiredFields(field.GetTypeName(), allMessageDescriptors, map[string]bool{})
+					defaultValue, err := cppDefaultValue(field, allEnumDescriptors)
+					if err != nil {
+						return fmt.Errorf("could not resolve default value: %w", err)
+					}
+					protoMessageField.DefaultValue = defaultValue
 					fieldPath := append(slices.Clone(currentPath), 2, int32(fieldIndex))
 					protoMessageField.Description = getComments(file.GetSourceCodeInfo(), fieldPath)
 
@@ -399,12 +624,20 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 						if protoMessageField.Description != "" {
 							protoMessageField.Description += "\n"
 						}
-						protoMessageField.Description += "Note: This field is a Google Protobuf Timestamp. In Godot, it is represented as an int64 (Unix timestamp in milliseconds)."
+						if cg.options.TimeType == TimeResource {
+							protoMessageField.Description += "Note: This field is a Google Protobuf Timestamp. In Godot, it is represented as a ProtoTimestamp (seconds and nanos since the Unix epoch)."
+						} else {
+							protoMessageField.Description += "Note: This field is a Google Protobuf Timestamp. In Godot, it is represented as an int64 (Unix timestamp in milliseconds)."
+						}
 					} else if protoMessageField.ProtoTypeName == ".google.protobuf.Duration" {
 						if protoMessageField.Description != "" {
 							protoMessageField.Description += "\n"
 						}
-						protoMessageField.Description += "Note: This field is a Google Protobuf Duration. In Godot, it is represented as a double (seconds)."
+						if cg.options.TimeType == TimeResource {
+							protoMessageField.Description += "Note: This field is a Google Protobuf Duration. In Godot, it is represented as a ProtoDuration (seconds and nanos)."
+						} else {
+							protoMessageField.Description += "Note: This field is a Google Protobuf Duration. In Godot, it is represented as a double (seconds)."
+						}
 					} else if protoMessageField.ProtoTypeName == ".google.protobuf.Struct" {
 						if protoMessageField.Description != "" {
 							protoMessageField.Description += "\n"
@@ -412,38 +645,46 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 						protoMessageField.Description += "Note: This field is a Google Protobuf Struct. In Godot, it is represented as a Dictionary."
 					}
 
-					godotType, godotClassName, isCustom, isEnum, srcFile, err := resolveGodotType(field, protoFile.ProtoPath, protoFileToDeclaredMessageNames, protoFileToDeclaredEnumNames, allMessageDescriptors, typeToGodotName)
+					godotType, godotClassName, isCustom, isEnum, srcFile, err := resolveGodotType(field, protoFile.ProtoPath, protoFileToDeclaredMessageNames, protoFileToDeclaredEnumNames, allMessageDescriptors, typeToClassName)
 					if err != nil {
 						return fmt.Errorf("could not resolve godot type: %w", err)
 					}
 
-					if isCustom && srcFile != "" && srcFile != "google::protobuf" && srcFile != protoFile.ProtoPath {
-						headerPath := strings.TrimSuffix(srcFile, ".proto") + ".h"
-						if !slices.Contains(protoFile.Dependencies, headerPath) {
-							protoFile.Dependencies = append(protoFile.Dependencies, headerPath)
-						}
+					if isCustom {
+						addDependency(godotType, srcFile)
+					}
 
-						// Add forward declaration
-						parts := strings.Split(godotType, "::")
-						if len(parts) > 1 {
-							className := parts[len(parts)-1]
-							namespace := strings.Join(parts[:len(parts)-1], "::")
-							fd := ForwardDecl{Namespace: namespace, ClassName: className}
-							exists := false
-							for _, existing := range protoFile.ForwardDecls {
-								if existing == fd {
-									exists = true
-									break
-								}
-							}
-							if !exists {
-								protoFile.ForwardDecls = append(protoFile.ForwardDecls, fd)
+					if className, ok := cg.timeResourceClassName(field); ok {
+						godotType, godotClassName, isCustom = "gdbuf::"+className, className, true
+					}
+
+					if policy := cg.uint64Policy(field); policy != "" {
+						godotType, godotClassName = uint64PolicyGodotType(policy)
+						protoMessageField.Uint64Policy = policy
+						if field.GetLabel() != descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+							if protoMessageField.DefaultValue, err = uint64PolicyDefaultValue(field, policy); err != nil {
+								return fmt.Errorf("could not resolve default value: %w", err)
 							}
 						}
 					}
 
+					if isWrapperType(field.GetTypeName()) {
+						protoMessageField.IsWrapper = true
+						if field.GetTypeName() == ".google.protobuf.UInt64Value" && cg.options.Uint64Policy != Uint64Wrap {
+							protoMessageField.Uint64Policy = cg.options.Uint64Policy
+						}
+					}
+
 					protoMessageField.IsCustomType = isCustom
 					protoMessageField.IsEnum = isEnum
+					protoMessageField.JSONName = jsonName(field)
+					protoMessageField.ValueKind = valueKind(field)
+					protoMessageField.WireType = wireType(field)
+					protoMessageField.VariantType = valueVariantType(field, godotType, isCustom, isEnum, protoMessageField.Uint64Policy)
+					if isEnum {
+						protoMessageField.EnumHint = enumHint(allEnumDescriptors[field.GetTypeName()])
+						protoMessageField.EnumClassName = enumToGodotName[field.GetTypeName()]
+					}
 
 					protoMessageField.InnerGodotType = godotType
 					protoMessageField.InnerGodotClassName = godotClassName
@@ -460,24 +701,57 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 								valueField = f
 							}
 						}
-						keyType, _, _, _, _, err := resolveGodotType(keyField, protoFile.ProtoPath, protoFileToDeclaredMessageNames, protoFileToDeclaredEnumNames, allMessageDescriptors, typeToGodotName)
+						keyType, _, _, _, _, err := resolveGodotType(keyField, protoFile.ProtoPath, protoFileToDeclaredMessageNames, protoFileToDeclaredEnumNames, allMessageDescriptors, typeToClassName)
 						if err != nil {
 							return fmt.Errorf("could not resolve map key type: %w", err)
 						}
-						valType, _, valCustom, _, _, err := resolveGodotType(valueField, protoFile.ProtoPath, protoFileToDeclaredMessageNames, protoFileToDeclaredEnumNames, allMessageDescriptors, typeToGodotName)
+						valType, valClassName, valCustom, valEnum, valSrcFile, err := resolveGodotType(valueField, protoFile.ProtoPath, protoFileToDeclaredMessageNames, protoFileToDeclaredEnumNames, allMessageDescriptors, typeToClassName)
 						if err != nil {
 							return fmt.Errorf("could not resolve map value type: %w", err)
 						}
+						if valCustom {
+							addDependency(valType, valSrcFile)
+						}
+						if className, ok := cg.timeResourceClassName(valueField); ok {
+							valType, valClassName, valCustom = "gdbuf::"+className, className, true
+						}
+						if policy := cg.uint64Policy(keyField); policy != "" {
+							keyType, _ = uint64PolicyGodotType(policy)
+							protoMessageField.MapKeyUint64Policy = policy
+						}
+						if policy := cg.uint64Policy(valueField); policy != "" {
+							valType, valClassName = uint64PolicyGodotType(policy)
+							protoMessageField.MapValueUint64Policy = policy
+						}
 						protoMessageField.MapKeyGodotType = keyType
 						protoMessageField.MapValueGodotType = valType
+						protoMessageField.MapValueGodotClassName = valClassName
+						protoMessageField.MapValueProtoTypeName = valueField.GetTypeName()
 						protoMessageField.MapValueIsCustom = valCustom
+						protoMessageField.MapValueIsEnum = valEnum
+						protoMessageField.MapValueIsWrapper = isWrapperType(valueField.GetTypeName())
+						if valueField.GetTypeName() == ".google.protobuf.UInt64Value" && cg.options.Uint64Policy != Uint64Wrap {
+							protoMessageField.MapValueUint64Policy = cg.options.Uint64Policy
+						}
+						protoMessageField.MapKeyKind = valueKind(keyField)
+						protoMessageField.MapValueKind = valueKind(valueField)
+						protoMessageField.MapKeyWireType = wireType(keyField)
+						protoMessageField.MapValueWireType = wireType(valueField)
+						protoMessageField.MapKeyVariantType = godotVariantType(keyType, false, false)
+						protoMessageField.MapValueVariantType = valueVariantType(valueField, valType, valCustom, valEnum, protoMessageField.MapValueUint64Policy)
 						protoMessageField.GodotType = "godot::Dictionary"
 						protoMessageField.GodotClassName = "Dictionary"
-						// For maps, we might need to know the value's class name if it's a custom object?
-						// The template for maps uses .MapValueGodotType (C++ type).
-						// If MapValueIsCustom is true, it does cast_to<{{ .MapValueGodotType }}>.
-						// It doesn't seem to use property hints for the dictionary content explicitly in the template I read earlier?
-						// Wait, let's recheck resource.cpp.tmpl.
+						var valEnumHint string
+						if valEnum {
+							valEnumHint = enumHint(allEnumDescriptors[valueField.GetTypeName()])
+							protoMessageField.MapValueEnumHint = valEnumHint
+						}
+						keyElementType, keyHint := typedElement(keyType, keyType, false, false, "")
+						valElementType, valHint := typedElement(valType, valClassName, valCustom, valEnum, valEnumHint)
+						if keyElementType != "" && valElementType != "" {
+							protoMessageField.GodotType = "godot::TypedDictionary<" + keyElementType + ", " + valElementType + ">"
+							protoMessageField.MapTypeHint = keyHint + ";" + valHint
+						}
 						protoMessageField.IsRepeated = false
 					} else {
 						protoMessageField.GodotType = godotType
@@ -487,6 +761,10 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 							protoMessageField.IsRepeated = true
 							protoMessageField.GodotType = "godot::Array"
 							protoMessageField.GodotClassName = "Array"
+							if elementType, hint := typedElement(godotType, godotClassName, isCustom, isEnum, protoMessageField.EnumHint); elementType != "" {
+								protoMessageField.GodotType = "godot::TypedArray<" + elementType + ">"
+								protoMessageField.ArrayTypeHint = hint
+							}
 							protoMessageField.IsCustomType = false
 							cg.logger.Debug("Repeated field", "name", field.GetName(), "after", protoMessageField.IsCustomType)
 						}
@@ -500,6 +778,7 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 					}
 
 					protoMessage.Fields = append(protoMessage.Fields, protoMessageField)
+					protoMessage.HasGroups = protoMessage.HasGroups || protoMessageField.IsGroup
 				}
 				cg.logger.Debug("Generated message", "name", protoMessage.MessageName, "fields", len(protoMessage.Fields))
 				messagesToGenerate = append(messagesToGenerate, protoMessage)
@@ -524,6 +803,163 @@ func (cg *CodeGenerator) extractProtoData(fileDescriptorSet []*descriptorpb.File
 	return &protoData, nil
 }
 
+// messageClassName returns the Godot class name of a message, godotName is its package-less
+// name with nested names joined by underscores.
+func (cg *CodeGenerator) messageClassName(pkg, godotName, fullName string) string {
+	if className, ok := cg.options.ClassNames[strings.TrimPrefix(fullName, ".")]; ok {
+		return className
+	}
+	if cg.options.ClassNaming == ClassNamingPackage {
+		return toPascalCase(strings.ReplaceAll(pkg, ".", "_")) + toPascalCase(godotName)
+	}
+	return toPascalCase(godotName)
+}
+
+// uint64Policy returns the overflow policy of a uint64 or fixed64 field, empty when the field is
+// stored as a plain uint64_t (the wrap policy).
+func (cg *CodeGenerator) uint64Policy(field *descriptorpb.FieldDescriptorProto) Uint64Policy {
+	if !isUint64Field(field) || cg.options.Uint64Policy == Uint64Wrap {
+		return ""
+	}
+	return cg.options.Uint64Policy
+}
+
+// timeResourceClassName returns the generated resource a Timestamp or Duration field is exposed as,
+// only with the TimeResource time type.
+func (cg *CodeGenerator) timeResourceClassName(field *descriptorpb.FieldDescriptorProto) (string, bool) {
+	if cg.options.TimeType != TimeResource {
+		return "", false
+	}
+	className, ok := timeResourceClasses[field.GetTypeName()]
+	return className, ok
+}
+
+// validateClassNames fails with every fully qualified message name that would be registered
+// under an already taken Godot class name. Godot keeps a single flat class namespace, a duplicate
+// GDREGISTER_CLASS only breaks at runtime.
+func (cg *CodeGenerator) validateClassNames(typeToClassName map[string]string, allMessageDescriptors map[string]*descriptorpb.DescriptorProto) error {
+	for fullName := range cg.options.ClassNames {
+		if _, ok := typeToClassName["."+fullName]; !ok {
+			cg.logger.Warn("class name mapping does not match any message", "message", fullName)
+		}
+	}
+
+	owners := map[string][]string{
+		// classes generated once per extension
+		cg.extensionName + "Any": {"the generated Any helper"},
+	}
+	if cg.options.TimeType == TimeResource {
+		for _, className := range timeResourceClasses {
+			owners[className] = []string{"the generated " + className + " resource"}
+		}
+	}
+	for fullName, className := range typeToClassName {
+		// map entries do not get a class of their own
+		if allMessageDescriptors[fullName].GetOptions().GetMapEntry() {
+			continue
+		}
+		owners[className] = append(owners[className], strings.TrimPrefix(fullName, "."))
+	}
+
+	var conflicts []string
+	for className, fullNames := range owners {
+		if len(fullNames) < 2 {
+			continue
+		}
+		slices.Sort(fullNames)
+		conflicts = append(conflicts, fmt.Sprintf("%s: %s", className, strings.Join(fullNames, ", ")))
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	slices.Sort(conflicts)
+	return fmt.Errorf("conflicting Godot class names, use the package class naming or map them to unique names:\n  %s", strings.Join(conflicts, "\n  "))
+}
+
+// LoadClassNames reads a JSON object mapping fully qualified message names to Godot class names,
+// e.g. {"game.v1.Player": "GamePlayer"}.
+func LoadClassNames(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read class name mapping: %w", err)
+	}
+	var classNames map[string]string
+	if err := json.Unmarshal(data, &classNames); err != nil {
+		return nil, fmt.Errorf("could not parse class name mapping %s: %w", path, err)
+	}
+	normalized := make(map[string]string, len(classNames))
+	for fullName, className := range classNames {
+		normalized[strings.TrimPrefix(fullName, ".")] = className
+	}
+	return normalized, nil
+}
+
+// fieldHasPresence reports whether the field tracks if it was set rather than only
+// holding a value: message fields, oneof members, proto3 `optional` and proto2 singular fields.
+// See https://protobuf.dev/programming-guides/field_presence/
+func fieldHasPresence(file *descriptorpb.FileDescriptorProto, field *descriptorpb.FieldDescriptorProto) bool {
+	if field.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		return false
+	}
+	// proto3 optional fields are members of a synthetic oneof
+	if field.OneofIndex != nil || isMessageField(field) {
+		return true
+	}
+	switch file.GetSyntax() {
+	case "proto3":
+		return false
+	case "editions":
+		presence := file.GetOptions().GetFeatures().GetFieldPresence()
+		if fieldPresence := field.GetOptions().GetFeatures().GetFieldPresence(); fieldPresence != descriptorpb.FeatureSet_FIELD_PRESENCE_UNKNOWN {
+			presence = fieldPresence
+		}
+		return presence != descriptorpb.FeatureSet_IMPLICIT
+	default:
+		// proto2
+		return true
+	}
+}
+
+// fieldIsRequired reports whether the field is a proto2 `required` field, or its editions equivalent.
+func fieldIsRequired(field *descriptorpb.FieldDescriptorProto) bool {
+	return field.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REQUIRED ||
+		field.GetOptions().GetFeatures().GetFieldPresence() == descriptorpb.FeatureSet_LEGACY_REQUIRED
+}
+
+func isMessageField(field *descriptorpb.FieldDescriptorProto) bool {
+	return field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE || field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP
+}
+
+// messageHasRequiredFields reports whether the message, or any message reachable through
+// its fields, declares required fields. Those messages need an initialization check.
+func messageHasRequiredFields(fullName string, allMessageDescriptors map[string]*descriptorpb.DescriptorProto, visited map[string]bool) bool {
+	if visited[fullName] {
+		return false
+	}
+	visited[fullName] = true
+
+	// well-known types are not in the map, none of them have required fields
+	msg, ok := allMessageDescriptors[fullName]
+	if !ok {
+		return false
+	}
+	for _, field := range msg.GetField() {
+		if fieldIsRequired(field) {
+			return true
+		}
+		if isMessageField(field) && messageHasRequiredFields(field.GetTypeName(), allMessageDescriptors, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWellKnownTypeFile reports whether the proto file is one of the google.protobuf
+// well-known type definitions that ship with protoc.
+func isWellKnownTypeFile(protoPath string) bool {
+	return strings.HasPrefix(protoPath, "google/protobuf/")
+}
+
 func getComments(sc *descriptorpb.SourceCodeInfo, path []int32) string {
 	if sc == nil {
 		return ""
@@ -542,24 +978,107 @@ func getComments(sc *descriptorpb.SourceCodeInfo, path []int32) string {
 	return ""
 }
 
-func (cg *CodeGenerator) extractGlobalEnums(fileDescriptorSet []*descriptorpb.FileDescriptorProto) []protoEnum {
-	var globalEnums []protoEnum
+// extractGlobalEnums groups the top-level enums into one class per enum scope. Scopes that map
+// onto the same class name, or enumerators that would clash inside one class, are reported
+// all at once, before any C++ is generated.
+func (cg *CodeGenerator) extractGlobalEnums(fileDescriptorSet []*descriptorpb.FileDescriptorProto, protoData *protoData) ([]protoEnumsClass, error) {
+	var globalEnums []protoEnumsClass
+	classIndex := make(map[string]int)
+	classScopes := make(map[string][]string)
 	for _, file := range fileDescriptorSet {
-		for _, enum := range file.GetEnumType() {
-			var protoEnum protoEnum
-			protoEnum.EnumName = enum.GetName()
-			enum.GetOptions().ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
-				protoEnum.Options = append(protoEnum.Options, fd.TextName())
-				return true
-			})
-			// Manual extraction of enum values because the above reflection might not get the values themselves easily if we just want names
-			// Actually, we need the values from the descriptor
-			protoEnum.Options = nil // Reset
-			for _, val := range enum.GetValue() {
-				protoEnum.Options = append(protoEnum.Options, val.GetName())
+		if len(file.GetEnumType()) == 0 {
+			continue
+		}
+		className := cg.enumsClassName(file)
+		scope := cg.enumScopeName(file)
+		if !slices.Contains(classScopes[className], scope) {
+			classScopes[className] = append(classScopes[className], scope)
+		}
+
+		index, ok := classIndex[className]
+		if !ok {
+			index = len(globalEnums)
+			classIndex[className] = index
+			globalEnums = append(globalEnums, protoEnumsClass{ClassName: className, Scope: scope})
+		}
+		for enumIndex, enum := range file.GetEnumType() {
+			// EnumType is field 5 in FileDescriptorProto
+			globalEnums[index].Enums = append(globalEnums[index].Enums, newProtoEnum(file, enum, []int32{5, int32(enumIndex)}))
+		}
+	}
+
+	var collisions []string
+	messageClasses := make(map[string]string)
+	for _, file := range protoData.Files {
+		for _, msg := range file.Messages {
+			messageClasses[msg.ClassName] = msg.FullName
+		}
+	}
+	for _, enumsClass := range globalEnums {
+		if scopes := classScopes[enumsClass.ClassName]; len(scopes) > 1 {
+			collisions = append(collisions, fmt.Sprintf("enums class %s is shared by %s", enumsClass.ClassName, strings.Join(scopes, ", ")))
+		}
+		if fullName, ok := messageClasses[enumsClass.ClassName]; ok {
+			collisions = append(collisions, fmt.Sprintf("enums class %s has the same name as the class of message %s", enumsClass.ClassName, fullName))
+		}
+		enumNames := make(map[string]bool)
+		valueNames := make(map[string]string)
+		for _, enum := range enumsClass.Enums {
+			if enumNames[enum.EnumName] {
+				collisions = append(collisions, fmt.Sprintf("enum %s is declared twice in %s", enum.EnumName, enumsClass.ClassName))
+			}
+			enumNames[enum.EnumName] = true
+			for _, value := range enum.Values {
+				if owner, ok := valueNames[value.Name]; ok {
+					collisions = append(collisions, fmt.Sprintf("enum value %s of %s collides with %s.%s in %s", value.Name, enum.EnumName, owner, value.Name, enumsClass.ClassName))
+					continue
+				}
+				valueNames[value.Name] = enum.EnumName
 			}
-			globalEnums = append(globalEnums, protoEnum)
 		}
 	}
-	return globalEnums
+	if len(collisions) > 0 {
+		return nil, fmt.Errorf("conflicting enum names, use a different enum scope or rename them:\n  %s", strings.Join(collisions, "\n  "))
+	}
+	return globalEnums, nil
+}
+
+// enumsClassName returns the name of the class the top-level enums of the file are registered on.
+// Enums without a package keep the plain <extension name>Enums class.
+func (cg *CodeGenerator) enumsClassName(file *descriptorpb.FileDescriptorProto) string {
+	var scope string
+	switch cg.options.EnumScope {
+	case EnumScopeFile:
+		scope = filepath.Base(strings.TrimSuffix(file.GetName(), ".proto"))
+	default:
+		scope = file.GetPackage()
+	}
+	scope = strings.NewReplacer(".", "_", "-", "_").Replace(scope)
+	return cg.extensionName + toPascalCase(scope) + "Enums"
+}
+
+// enumScopeName describes the enum scope of the file in docs and error messages.
+func (cg *CodeGenerator) enumScopeName(file *descriptorpb.FileDescriptorProto) string {
+	if cg.options.EnumScope == EnumScopeFile {
+		return file.GetName()
+	}
+	if file.GetPackage() == "" {
+		return "the root package"
+	}
+	return "package " + file.GetPackage()
+}
+
+// newProtoEnum collects the template data of an enum, path is the enum's source code info location
+// used to look up the value comments.
+func newProtoEnum(file *descriptorpb.FileDescriptorProto, enum *descriptorpb.EnumDescriptorProto, path []int32) protoEnum {
+	protoEnum := protoEnum{EnumName: enum.GetName()}
+	for valueIndex, value := range enum.GetValue() {
+		// Value is field 2 in EnumDescriptorProto
+		protoEnum.Values = append(protoEnum.Values, protoEnumValue{
+			Name:        value.GetName(),
+			Number:      value.GetNumber(),
+			Description: getComments(file.GetSourceCodeInfo(), append(slices.Clone(path), 2, int32(valueIndex))),
+		})
+	}
+	return protoEnum
 }
@@ -0,0 +1,716 @@
+package codegen
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFieldHasPresence(t *testing.T) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	int32Type := descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+	messageType := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+
+	tests := []struct {
+		name   string
+		syntax string
+		field  *descriptorpb.FieldDescriptorProto
+		want   bool
+	}{
+		{
+			name:   "proto3 implicit scalar",
+			syntax: "proto3",
+			field:  &descriptorpb.FieldDescriptorProto{Label: optional, Type: int32Type},
+			want:   false,
+		},
+		{
+			name:   "proto3 optional scalar",
+			syntax: "proto3",
+			field:  &descriptorpb.FieldDescriptorProto{Label: optional, Type: int32Type, OneofIndex: proto.Int32(0), Proto3Optional: proto.Bool(true)},
+			want:   true,
+		},
+		{
+			name:   "proto3 message",
+			syntax: "proto3",
+			field:  &descriptorpb.FieldDescriptorProto{Label: optional, Type: messageType},
+			want:   true,
+		},
+		{
+			name:   "proto3 repeated message",
+			syntax: "proto3",
+			field:  &descriptorpb.FieldDescriptorProto{Label: repeated, Type: messageType},
+			want:   false,
+		},
+		{
+			name:   "proto3 oneof member",
+			syntax: "proto3",
+			field:  &descriptorpb.FieldDescriptorProto{Label: optional, Type: int32Type, OneofIndex: proto.Int32(0)},
+			want:   true,
+		},
+		{
+			name:   "proto2 optional scalar",
+			syntax: "",
+			field:  &descriptorpb.FieldDescriptorProto{Label: optional, Type: int32Type},
+			want:   true,
+		},
+		{
+			name:   "proto2 repeated scalar",
+			syntax: "proto2",
+			field:  &descriptorpb.FieldDescriptorProto{Label: repeated, Type: int32Type},
+			want:   false,
+		},
+		{
+			name:   "editions default scalar",
+			syntax: "editions",
+			field:  &descriptorpb.FieldDescriptorProto{Label: optional, Type: int32Type},
+			want:   true,
+		},
+		{
+			name:   "editions implicit scalar",
+			syntax: "editions",
+			field: &descriptorpb.FieldDescriptorProto{Label: optional, Type: int32Type, Options: &descriptorpb.FieldOptions{
+				Features: &descriptorpb.FeatureSet{FieldPresence: descriptorpb.FeatureSet_IMPLICIT.Enum()},
+			}},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &descriptorpb.FileDescriptorProto{Syntax: proto.String(tt.syntax)}
+			if got := fieldHasPresence(file, tt.field); got != tt.want {
+				t.Errorf("fieldHasPresence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageHasRequiredFields(t *testing.T) {
+	required := descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum()
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	messageType := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	int32Type := descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+
+	all := map[string]*descriptorpb.DescriptorProto{
+		".Leaf": {Field: []*descriptorpb.FieldDescriptorProto{
+			{Name: proto.String("id"), Label: required, Type: int32Type},
+		}},
+		".Parent": {Field: []*descriptorpb.FieldDescriptorProto{
+			{Name: proto.String("leaf"), Label: optional, Type: messageType, TypeName: proto.String(".Leaf")},
+		}},
+		".Cycle": {Field: []*descriptorpb.FieldDescriptorProto{
+			{Name: proto.String("self"), Label: optional, Type: messageType, TypeName: proto.String(".Cycle")},
+			{Name: proto.String("ts"), Label: optional, Type: messageType, TypeName: proto.String(".google.protobuf.Timestamp")},
+		}},
+	}
+
+	for name, want := range map[string]bool{".Leaf": true, ".Parent": true, ".Cycle": false, ".Unknown": false} {
+		if got := messageHasRequiredFields(name, all, map[string]bool{}); got != want {
+			t.Errorf("messageHasRequiredFields(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestExtractProtoDataNestedEnums(t *testing.T) {
+	enumType := descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum()
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("game.proto"),
+		Package: proto.String("game"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Outer"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("mood"), Number: proto.Int32(1), Label: optional, Type: enumType, TypeName: proto.String(".game.Outer.Middle.Inner.Mood")},
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Middle"),
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("Inner"),
+					EnumType: []*descriptorpb.EnumDescriptorProto{{
+						Name: proto.String("Mood"),
+						Value: []*descriptorpb.EnumValueDescriptorProto{
+							{Name: proto.String("MOOD_UNSPECIFIED"), Number: proto.Int32(0)},
+							{Name: proto.String("MOOD_HAPPY"), Number: proto.Int32(5)},
+						},
+					}},
+				}},
+			}},
+		}},
+	}
+
+	cg, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{})
+	if err != nil {
+		t.Fatalf("NewCodeGenerator() error = %v", err)
+	}
+	data, err := cg.extractProtoData([]*descriptorpb.FileDescriptorProto{file})
+	if err != nil {
+		t.Fatalf("extractProtoData() error = %v", err)
+	}
+
+	messages := map[string]protoMessage{}
+	for _, msg := range data.Files[0].Messages {
+		messages[msg.ClassName] = msg
+	}
+	inner := messages["OuterMiddleInner"]
+	if len(inner.Enums) != 1 || inner.Enums[0].EnumName != "Mood" || len(inner.Enums[0].Values) != 2 || inner.Enums[0].Values[1].Number != 5 {
+		t.Errorf("nested enum not attached to its owning message, got %+v", inner.Enums)
+	}
+	if len(messages["Outer"].Enums) != 0 {
+		t.Errorf("enum attached to the wrong message, got %+v", messages["Outer"].Enums)
+	}
+	field := messages["Outer"].Fields[0]
+	if field.EnumClassName != "OuterMiddleInner.Mood" {
+		t.Errorf("EnumClassName = %v, want OuterMiddleInner.Mood", field.EnumClassName)
+	}
+	if field.EnumHint != "MOOD_UNSPECIFIED:0,MOOD_HAPPY:5" {
+		t.Errorf("EnumHint = %v", field.EnumHint)
+	}
+}
+
+func TestExtractGlobalEnums(t *testing.T) {
+	enumFile := func(name, pkg string, values ...string) *descriptorpb.FileDescriptorProto {
+		enum := &descriptorpb.EnumDescriptorProto{Name: proto.String("Status")}
+		for i, value := range values {
+			enum.Value = append(enum.Value, &descriptorpb.EnumValueDescriptorProto{Name: proto.String(value), Number: proto.Int32(int32(i))})
+		}
+		return &descriptorpb.FileDescriptorProto{Name: proto.String(name), Package: proto.String(pkg), EnumType: []*descriptorpb.EnumDescriptorProto{enum}}
+	}
+
+	tests := []struct {
+		name        string
+		scope       EnumScope
+		files       []*descriptorpb.FileDescriptorProto
+		wantClasses []string
+		wantErr     []string
+	}{
+		{
+			name:  "same enum in two packages",
+			scope: EnumScopePackage,
+			files: []*descriptorpb.FileDescriptorProto{
+				enumFile("game/v1/game.proto", "game.v1", "UNKNOWN"),
+				enumFile("admin/v1/admin.proto", "admin.v1", "UNKNOWN"),
+				enumFile("root.proto", "", "UNKNOWN"),
+			},
+			wantClasses: []string{"gdbufgenGameV1Enums", "gdbufgenAdminV1Enums", "gdbufgenEnums"},
+		},
+		{
+			name:  "file scope",
+			scope: EnumScopeFile,
+			files: []*descriptorpb.FileDescriptorProto{
+				enumFile("game/v1/player.proto", "game.v1", "UNKNOWN"),
+				enumFile("game/v1/match-state.proto", "game.v1", "MATCH_UNKNOWN"),
+			},
+			wantClasses: []string{"gdbufgenPlayerEnums", "gdbufgenMatchStateEnums"},
+		},
+		{
+			name:  "packages sharing a class name",
+			scope: EnumScopePackage,
+			files: []*descriptorpb.FileDescriptorProto{
+				enumFile("a.proto", "game.v1", "UNKNOWN"),
+				enumFile("b.proto", "game_v1", "UNKNOWN"),
+			},
+			wantErr: []string{
+				"enums class gdbufgenGameV1Enums is shared by package game.v1, package game_v1",
+				"enum Status is declared twice in gdbufgenGameV1Enums",
+				"enum value UNKNOWN of Status collides with Status.UNKNOWN in gdbufgenGameV1Enums",
+			},
+		},
+		{
+			name:  "files sharing a class name",
+			scope: EnumScopeFile,
+			files: []*descriptorpb.FileDescriptorProto{
+				enumFile("game/types.proto", "game", "GAME_UNKNOWN"),
+				enumFile("admin/types.proto", "admin", "ADMIN_UNKNOWN"),
+			},
+			wantErr: []string{"enums class gdbufgenTypesEnums is shared by game/types.proto, admin/types.proto"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cg, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{EnumScope: tt.scope})
+			if err != nil {
+				t.Fatalf("NewCodeGenerator() error = %v", err)
+			}
+			got, err := cg.extractGlobalEnums(tt.files, &protoData{})
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatalf("extractGlobalEnums() expected an error")
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("extractGlobalEnums() error = %v, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractGlobalEnums() error = %v", err)
+			}
+			var gotClasses []string
+			for _, enumsClass := range got {
+				gotClasses = append(gotClasses, enumsClass.ClassName)
+			}
+			if !slices.Equal(gotClasses, tt.wantClasses) {
+				t.Errorf("extractGlobalEnums() classes = %v, want %v", gotClasses, tt.wantClasses)
+			}
+		})
+	}
+}
+
+func TestExtractGlobalEnumsMessageCollision(t *testing.T) {
+	cg, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{})
+	if err != nil {
+		t.Fatalf("NewCodeGenerator() error = %v", err)
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:     proto.String("root.proto"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{Name: proto.String("Status"), Value: []*descriptorpb.EnumValueDescriptorProto{{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)}}}},
+	}
+	data := &protoData{Files: []protoFile{{Messages: []protoMessage{{ClassName: "gdbufgenEnums", FullName: "gdbufgenEnums"}}}}}
+	if _, err := cg.extractGlobalEnums([]*descriptorpb.FileDescriptorProto{file}, data); err == nil || !strings.Contains(err.Error(), "same name as the class of message gdbufgenEnums") {
+		t.Errorf("extractGlobalEnums() error = %v, want a message class collision", err)
+	}
+}
+
+func TestNewCodeGeneratorUnknownEnumScope(t *testing.T) {
+	if _, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{EnumScope: "module"}); err == nil {
+		t.Errorf("NewCodeGenerator() expected error for unknown enum scope")
+	}
+}
+
+func TestExtractProtoDataClassNaming(t *testing.T) {
+	playerFile := func(name, pkg string) *descriptorpb.FileDescriptorProto {
+		return &descriptorpb.FileDescriptorProto{
+			Name:    proto.String(name),
+			Package: proto.String(pkg),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name:       proto.String("Player"),
+				NestedType: []*descriptorpb.DescriptorProto{{Name: proto.String("Stats")}},
+			}},
+		}
+	}
+	admin := playerFile("admin/v1/player.proto", "admin.v1")
+	admin.MessageType[0].Field = []*descriptorpb.FieldDescriptorProto{{
+		Name:     proto.String("target"),
+		Number:   proto.Int32(1),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+		TypeName: proto.String(".game.v1.Player"),
+	}}
+	files := []*descriptorpb.FileDescriptorProto{playerFile("game/v1/player.proto", "game.v1"), admin}
+
+	tests := []struct {
+		name        string
+		options     Options
+		wantClasses []string
+		wantField   string
+		wantErr     []string
+	}{
+		{
+			name:    "bare names collide",
+			options: Options{},
+			wantErr: []string{
+				"Player: admin.v1.Player, game.v1.Player",
+				"PlayerStats: admin.v1.Player.Stats, game.v1.Player.Stats",
+			},
+		},
+		{
+			name:        "package prefixed",
+			options:     Options{ClassNaming: ClassNamingPackage},
+			wantClasses: []string{"GameV1Player", "GameV1PlayerStats", "AdminV1Player", "AdminV1PlayerStats"},
+			wantField:   "gdbuf::player::GameV1Player",
+		},
+		{
+			name: "mapping file",
+			options: Options{ClassNames: map[string]string{
+				"admin.v1.Player":       "AdminPlayer",
+				"admin.v1.Player.Stats": "AdminPlayerStats",
+			}},
+			wantClasses: []string{"Player", "PlayerStats", "AdminPlayer", "AdminPlayerStats"},
+			wantField:   "gdbuf::player::Player",
+		},
+		{
+			name:    "mapping onto a taken name",
+			options: Options{ClassNaming: ClassNamingPackage, ClassNames: map[string]string{"admin.v1.Player": "GameV1Player"}},
+			wantErr: []string{"GameV1Player: admin.v1.Player, game.v1.Player"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cg, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", tt.options)
+			if err != nil {
+				t.Fatalf("NewCodeGenerator() error = %v", err)
+			}
+			data, err := cg.extractProtoData(files)
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatalf("extractProtoData() expected an error")
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("extractProtoData() error = %v, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractProtoData() error = %v", err)
+			}
+			var gotClasses []string
+			for _, file := range data.Files {
+				for _, msg := range file.Messages {
+					gotClasses = append(gotClasses, msg.ClassName)
+				}
+			}
+			if !slices.Equal(gotClasses, tt.wantClasses) {
+				t.Errorf("extractProtoData() classes = %v, want %v", gotClasses, tt.wantClasses)
+			}
+			if got := data.Files[1].Messages[0].Fields[0].GodotType; got != tt.wantField {
+				t.Errorf("cross package field type = %v, want %v", got, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestNewCodeGeneratorInvalidClassNames(t *testing.T) {
+	for name, options := range map[string]Options{
+		"unknown naming": {ClassNaming: "short"},
+		"invalid name":   {ClassNames: map[string]string{"game.Player": "Game.Player"}},
+	} {
+		if _, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", options); err == nil {
+			t.Errorf("NewCodeGenerator() expected error for %s", name)
+		}
+	}
+}
+
+func TestLoadClassNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "class_names.json")
+	if err := os.WriteFile(path, []byte(`{".game.v1.Player": "GamePlayer", "admin.v1.Player": "AdminPlayer"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadClassNames(path)
+	if err != nil {
+		t.Fatalf("LoadClassNames() error = %v", err)
+	}
+	if got["game.v1.Player"] != "GamePlayer" || got["admin.v1.Player"] != "AdminPlayer" {
+		t.Errorf("LoadClassNames() = %v", got)
+	}
+}
+
+func TestExtractProtoDataUint64Policy(t *testing.T) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:   proto.String("counters.proto"),
+		Syntax: proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Counters"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("total"), Number: proto.Int32(1), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_UINT64.Enum()},
+				{Name: proto.String("hashes"), Number: proto.Int32(2), Label: repeated, Type: descriptorpb.FieldDescriptorProto_TYPE_FIXED64.Enum()},
+				{Name: proto.String("small"), Number: proto.Int32(3), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_FIXED32.Enum()},
+			},
+		}},
+	}
+
+	tests := []struct {
+		policy      Uint64Policy
+		wantType    string
+		wantPolicy  Uint64Policy
+		wantDefault string
+	}{
+		{policy: Uint64Wrap, wantType: "uint64_t"},
+		{policy: Uint64Clamp, wantType: "int64_t", wantPolicy: Uint64Clamp},
+		{policy: Uint64String, wantType: "godot::String", wantPolicy: Uint64String, wantDefault: `godot::String("0")`},
+		{policy: Uint64Bytes, wantType: "godot::PackedByteArray", wantPolicy: Uint64Bytes, wantDefault: "GDBufUtils::uint64_to_bytes(0ULL)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			cg, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{Uint64Policy: tt.policy})
+			if err != nil {
+				t.Fatalf("NewCodeGenerator() error = %v", err)
+			}
+			data, err := cg.extractProtoData([]*descriptorpb.FileDescriptorProto{file})
+			if err != nil {
+				t.Fatalf("extractProtoData() error = %v", err)
+			}
+			fields := data.Files[0].Messages[0].Fields
+			if fields[0].GodotType != tt.wantType || fields[0].Uint64Policy != tt.wantPolicy || fields[0].DefaultValue != tt.wantDefault {
+				t.Errorf("uint64 field = %s, %q, %q, want %s, %q, %q", fields[0].GodotType, fields[0].Uint64Policy, fields[0].DefaultValue, tt.wantType, tt.wantPolicy, tt.wantDefault)
+			}
+			if fields[1].InnerGodotType != tt.wantType || fields[1].Uint64Policy != tt.wantPolicy || fields[1].DefaultValue != "" {
+				t.Errorf("repeated fixed64 field = %s, %q, %q, want %s, %q without default", fields[1].InnerGodotType, fields[1].Uint64Policy, fields[1].DefaultValue, tt.wantType, tt.wantPolicy)
+			}
+			if fields[2].GodotType != "uint32_t" || fields[2].Uint64Policy != "" {
+				t.Errorf("fixed32 field = %s, %q, want uint32_t without policy", fields[2].GodotType, fields[2].Uint64Policy)
+			}
+		})
+	}
+}
+
+func TestExtractProtoDataTimeType(t *testing.T) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	message := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("replay.proto"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto", "google/protobuf/duration.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Tick"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("at"), Number: proto.Int32(1), Label: optional, Type: message, TypeName: proto.String(".google.protobuf.Timestamp")},
+				{Name: proto.String("elapsed"), Number: proto.Int32(2), Label: repeated, Type: message, TypeName: proto.String(".google.protobuf.Duration")},
+			},
+		}},
+	}
+
+	tests := []struct {
+		timeType        TimeType
+		wantType        string
+		wantElapsedType string
+		wantCustom      bool
+	}{
+		{timeType: TimeNative, wantType: "int64_t", wantElapsedType: "double"},
+		{timeType: TimeResource, wantType: "gdbuf::ProtoTimestamp", wantElapsedType: "gdbuf::ProtoDuration", wantCustom: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.timeType), func(t *testing.T) {
+			cg, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{TimeType: tt.timeType})
+			if err != nil {
+				t.Fatalf("NewCodeGenerator() error = %v", err)
+			}
+			data, err := cg.extractProtoData([]*descriptorpb.FileDescriptorProto{file})
+			if err != nil {
+				t.Fatalf("extractProtoData() error = %v", err)
+			}
+			fields := data.Files[0].Messages[0].Fields
+			if fields[0].GodotType != tt.wantType || fields[0].IsCustomType != tt.wantCustom {
+				t.Errorf("timestamp field = %s, custom %v, want %s, custom %v", fields[0].GodotType, fields[0].IsCustomType, tt.wantType, tt.wantCustom)
+			}
+			if fields[1].InnerGodotType != tt.wantElapsedType || fields[1].IsInnerCustomType != tt.wantCustom {
+				t.Errorf("repeated duration field = %s, custom %v, want %s, custom %v", fields[1].InnerGodotType, fields[1].IsInnerCustomType, tt.wantElapsedType, tt.wantCustom)
+			}
+		})
+	}
+}
+
+func TestExtractProtoDataGroups(t *testing.T) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	group := descriptorpb.FieldDescriptorProto_TYPE_GROUP.Enum()
+	message := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:   proto.String("legacy.proto"),
+		Syntax: proto.String("proto2"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Legacy"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("settings"), Number: proto.Int32(1), Label: optional, Type: group, TypeName: proto.String(".Legacy.Settings")},
+				{Name: proto.String("child"), Number: proto.Int32(2), Label: optional, Type: message, TypeName: proto.String(".Legacy.Settings")},
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{Name: proto.String("Settings")}},
+		}},
+	}
+
+	cg, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{})
+	if err != nil {
+		t.Fatalf("NewCodeGenerator() error = %v", err)
+	}
+	data, err := cg.extractProtoData([]*descriptorpb.FileDescriptorProto{file})
+	if err != nil {
+		t.Fatalf("extractProtoData() error = %v", err)
+	}
+	legacy, settings := data.Files[0].Messages[0], data.Files[0].Messages[1]
+	if !legacy.HasGroups || settings.HasGroups {
+		t.Errorf("HasGroups = %v, %v, want true, false", legacy.HasGroups, settings.HasGroups)
+	}
+	if !legacy.Fields[0].IsGroup || !legacy.Fields[0].IsCustomType {
+		t.Errorf("group field IsGroup = %v, custom %v, want true, true", legacy.Fields[0].IsGroup, legacy.Fields[0].IsCustomType)
+	}
+	if legacy.Fields[1].IsGroup {
+		t.Errorf("message field IsGroup = true, want false")
+	}
+}
+
+func TestNewCodeGeneratorUnknownTimeType(t *testing.T) {
+	if _, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{TimeType: "chrono"}); err == nil {
+		t.Errorf("NewCodeGenerator() expected error for unknown time type")
+	}
+}
+
+func TestExtractProtoDataMapTypes(t *testing.T) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	mapEntry := func(name string, value *descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		value.Name, value.Number, value.Label = proto.String("value"), proto.Int32(2), optional
+		return &descriptorpb.DescriptorProto{
+			Name:    proto.String(name),
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("key"), Number: proto.Int32(1), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				value,
+			},
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:     proto.String("inventory.proto"),
+		Syntax:   proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{Name: proto.String("Rarity"), Value: []*descriptorpb.EnumValueDescriptorProto{{Name: proto.String("COMMON"), Number: proto.Int32(0)}}}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Item")},
+			{
+				Name: proto.String("Inventory"),
+				NestedType: []*descriptorpb.DescriptorProto{
+					mapEntry("ItemsEntry", &descriptorpb.FieldDescriptorProto{Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".Item")}),
+					mapEntry("RaritiesEntry", &descriptorpb.FieldDescriptorProto{Type: descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(), TypeName: proto.String(".Rarity")}),
+					mapEntry("CountsEntry", &descriptorpb.FieldDescriptorProto{Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".google.protobuf.Int32Value")}),
+					mapEntry("IconsEntry", &descriptorpb.FieldDescriptorProto{Type: descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()}),
+					mapEntry("ExpiriesEntry", &descriptorpb.FieldDescriptorProto{Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".google.protobuf.Timestamp")}),
+					mapEntry("CooldownsEntry", &descriptorpb.FieldDescriptorProto{Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".google.protobuf.Duration")}),
+					mapEntry("MetadataEntry", &descriptorpb.FieldDescriptorProto{Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".google.protobuf.Struct")}),
+				},
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("items"), Number: proto.Int32(1), Label: repeated, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".Inventory.ItemsEntry")},
+					{Name: proto.String("rarities"), Number: proto.Int32(2), Label: repeated, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".Inventory.RaritiesEntry")},
+					{Name: proto.String("counts"), Number: proto.Int32(3), Label: repeated, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".Inventory.CountsEntry")},
+					{Name: proto.String("icons"), Number: proto.Int32(4), Label: repeated, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".Inventory.IconsEntry")},
+					{Name: proto.String("expiries"), Number: proto.Int32(5), Label: repeated, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".Inventory.ExpiriesEntry")},
+					{Name: proto.String("cooldowns"), Number: proto.Int32(6), Label: repeated, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".Inventory.CooldownsEntry")},
+					{Name: proto.String("metadata"), Number: proto.Int32(7), Label: repeated, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".Inventory.MetadataEntry")},
+				},
+			},
+		},
+	}
+
+	cg, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{})
+	if err != nil {
+		t.Fatalf("NewCodeGenerator() error = %v", err)
+	}
+	data, err := cg.extractProtoData([]*descriptorpb.FileDescriptorProto{file})
+	if err != nil {
+		t.Fatalf("extractProtoData() error = %v", err)
+	}
+	var fields []protoMessageField
+	for _, msg := range data.Files[0].Messages {
+		if msg.ClassName == "Inventory" {
+			fields = msg.Fields
+		}
+	}
+
+	tests := []struct {
+		field         string
+		wantType      string
+		wantHint      string
+		wantValueType string
+		wantKind      string
+		wantEnum      bool
+		wantWrapper   bool
+	}{
+		{field: "items", wantType: "godot::TypedDictionary<godot::String, Item>", wantHint: "String;Item", wantValueType: ".Item", wantKind: "GDBufUtils::VALUE_MESSAGE"},
+		{field: "rarities", wantType: "godot::TypedDictionary<godot::String, int32_t>", wantHint: "String;2/2:COMMON:0", wantValueType: ".Rarity", wantKind: "GDBufUtils::VALUE_ENUM", wantEnum: true},
+		{field: "counts", wantType: "godot::Dictionary", wantValueType: ".google.protobuf.Int32Value", wantKind: "GDBufUtils::VALUE_INT32", wantWrapper: true},
+		{field: "icons", wantType: "godot::TypedDictionary<godot::String, godot::PackedByteArray>", wantHint: "String;PackedByteArray", wantKind: "GDBufUtils::VALUE_BYTES"},
+		{field: "expiries", wantType: "godot::TypedDictionary<godot::String, int64_t>", wantHint: "String;int", wantValueType: ".google.protobuf.Timestamp", wantKind: "GDBufUtils::VALUE_TIMESTAMP"},
+		{field: "cooldowns", wantType: "godot::TypedDictionary<godot::String, double>", wantHint: "String;float", wantValueType: ".google.protobuf.Duration", wantKind: "GDBufUtils::VALUE_DURATION"},
+		{field: "metadata", wantType: "godot::TypedDictionary<godot::String, godot::Dictionary>", wantHint: "String;Dictionary", wantValueType: ".google.protobuf.Struct", wantKind: "GDBufUtils::VALUE_STRUCT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			idx := slices.IndexFunc(fields, func(f protoMessageField) bool { return f.FieldName == tt.field })
+			if idx == -1 {
+				t.Fatalf("field %s not generated", tt.field)
+			}
+			field := fields[idx]
+			if field.GodotType != tt.wantType || field.MapTypeHint != tt.wantHint {
+				t.Errorf("map field = %s, %q, want %s, %q", field.GodotType, field.MapTypeHint, tt.wantType, tt.wantHint)
+			}
+			if field.MapValueProtoTypeName != tt.wantValueType || field.MapValueIsEnum != tt.wantEnum || field.MapValueIsWrapper != tt.wantWrapper {
+				t.Errorf("map value = %s, enum %v, wrapper %v, want %s, enum %v, wrapper %v", field.MapValueProtoTypeName, field.MapValueIsEnum, field.MapValueIsWrapper, tt.wantValueType, tt.wantEnum, tt.wantWrapper)
+			}
+			if field.MapValueKind != tt.wantKind {
+				t.Errorf("map value kind = %s, want %s", field.MapValueKind, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestExtractProtoDataMapValueFromOtherPackage(t *testing.T) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	shared := &descriptorpb.FileDescriptorProto{
+		Name:        proto.String("shared/thing.proto"),
+		Package:     proto.String("shared"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Thing")}},
+	}
+	owner := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("owner.proto"),
+		Package:    proto.String("owner"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"shared/thing.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Owner"),
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name:    proto.String("ThingsEntry"),
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("key"), Number: proto.Int32(1), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+					{Name: proto.String("value"), Number: proto.Int32(2), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".shared.Thing")},
+				},
+			}},
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("things"), Number: proto.Int32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".owner.Owner.ThingsEntry")},
+			},
+		}},
+	}
+
+	cg, err := NewCodeGenerator(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir(), "gdbufgen", "", Options{})
+	if err != nil {
+		t.Fatalf("NewCodeGenerator() error = %v", err)
+	}
+	data, err := cg.extractProtoData([]*descriptorpb.FileDescriptorProto{shared, owner})
+	if err != nil {
+		t.Fatalf("extractProtoData() error = %v", err)
+	}
+	ownerFile := data.Files[1]
+	field := ownerFile.Messages[0].Fields[0]
+	if field.MapValueGodotType != "gdbuf::thing::Thing" || field.MapValueProtoTypeName != ".shared.Thing" {
+		t.Errorf("map value = %s, %s, want gdbuf::thing::Thing, .shared.Thing", field.MapValueGodotType, field.MapValueProtoTypeName)
+	}
+	if !slices.Contains(ownerFile.Dependencies, "shared/thing.h") {
+		t.Errorf("Dependencies = %v, want shared/thing.h included for the map value", ownerFile.Dependencies)
+	}
+}
+
+func TestNanopbOrder(t *testing.T) {
+	fields := []protoMessageField{
+		{FieldName: "c", Number: 3},
+		{FieldName: "d", Number: 4, OneofName: "choice"},
+		{FieldName: "a", Number: 1},
+		{FieldName: "b", Number: 2, OneofName: "choice"},
+		{FieldName: "e", Number: 5},
+	}
+	order := getTemplateFuncMap()["nanopbOrder"].(func([]protoMessageField) []protoMessageField)
+
+	var names []string
+	for _, field := range order(fields) {
+		names = append(names, field.FieldName)
+	}
+	// the oneof members follow each other at the position of b
+	if want := []string{"a", "b", "d", "c", "e"}; !slices.Equal(names, want) {
+		t.Errorf("nanopbOrder() = %v, want %v", names, want)
+	}
+}
@@ -2,9 +2,12 @@ package codegen
 
 import (
 	"fmt"
+	"math"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"google.golang.org/protobuf/types/descriptorpb"
 )
@@ -21,8 +24,8 @@ var protoGodotTypeMap = map[descriptorpb.FieldDescriptorProto_Type]string{
 	descriptorpb.FieldDescriptorProto_TYPE_UINT64:   "uint64_t",
 	descriptorpb.FieldDescriptorProto_TYPE_SINT32:   "int32_t",
 	descriptorpb.FieldDescriptorProto_TYPE_SINT64:   "int64_t",
-	descriptorpb.FieldDescriptorProto_TYPE_FIXED32:  "int32_t",
-	descriptorpb.FieldDescriptorProto_TYPE_FIXED64:  "int64_t",
+	descriptorpb.FieldDescriptorProto_TYPE_FIXED32:  "uint32_t",
+	descriptorpb.FieldDescriptorProto_TYPE_FIXED64:  "uint64_t",
 	descriptorpb.FieldDescriptorProto_TYPE_SFIXED32: "int32_t",
 	descriptorpb.FieldDescriptorProto_TYPE_SFIXED64: "int64_t",
 }
@@ -32,28 +35,215 @@ type godotTypeInfo struct {
 	GodotClassName string
 }
 
+// timeResourceClasses maps Timestamp and Duration onto the resources generated for the TimeResource time type
+var timeResourceClasses = map[string]string{
+	".google.protobuf.Timestamp": "ProtoTimestamp",
+	".google.protobuf.Duration":  "ProtoDuration",
+}
+
 var wktMap = map[string]godotTypeInfo{
-	"Timestamp":   {"int64_t", "int"},
-	"Duration":    {"double", "float"},
-	"Struct":      {"godot::Dictionary", "Dictionary"},
-	"Any":         {"godot::Dictionary", "Dictionary"},
-	"ListValue":   {"godot::Array", "Array"},
-	"Value":       {"godot::Variant", "Variant"},
-	"Empty":       {"godot::Variant", "Variant"},
-	"StringValue": {"godot::String", "String"},
-	"Int32Value":  {"int32_t", "int"},
-	"BoolValue":   {"bool", "bool"},
-	"FieldMask":   {"godot::PackedStringArray", "PackedStringArray"},
-}
-
-func resolveGodotType(field *descriptorpb.FieldDescriptorProto, currentProtoPath string, fileToMsgs map[string][]string, fileToEnum map[string][]string, allMessageDescriptors map[string]*descriptorpb.DescriptorProto, typeToGodotName map[string]string) (godotType string, godotClassName string, isCustom bool, isEnum bool, srcFile string, err error) {
+	"Timestamp": {"int64_t", "int"},
+	"Duration":  {"double", "float"},
+	"Struct":    {"godot::Dictionary", "Dictionary"},
+	"Any":       {"godot::Dictionary", "Dictionary"},
+	"ListValue": {"godot::Array", "Array"},
+	"Value":     {"godot::Variant", "Variant"},
+	"Empty":     {"godot::Variant", "Variant"},
+	"FieldMask": {"godot::PackedStringArray", "PackedStringArray"},
+	// wrappers are nullable, null when unset
+	"DoubleValue": {"godot::Variant", "Variant"},
+	"FloatValue":  {"godot::Variant", "Variant"},
+	"Int64Value":  {"godot::Variant", "Variant"},
+	"UInt64Value": {"godot::Variant", "Variant"},
+	"Int32Value":  {"godot::Variant", "Variant"},
+	"UInt32Value": {"godot::Variant", "Variant"},
+	"BoolValue":   {"godot::Variant", "Variant"},
+	"StringValue": {"godot::Variant", "Variant"},
+	"BytesValue":  {"godot::Variant", "Variant"},
+}
+
+// isWrapperType reports whether the type is one of the google/protobuf/wrappers.proto messages.
+func isWrapperType(typeName string) bool {
+	switch typeName {
+	case ".google.protobuf.DoubleValue", ".google.protobuf.FloatValue",
+		".google.protobuf.Int64Value", ".google.protobuf.UInt64Value",
+		".google.protobuf.Int32Value", ".google.protobuf.UInt32Value",
+		".google.protobuf.BoolValue", ".google.protobuf.StringValue", ".google.protobuf.BytesValue":
+		return true
+	}
+	return false
+}
+
+// valueKinds maps the well-known types converted by GDBufUtils to their GDBufUtils::ValueKind,
+// wrappers take the kind of the value they wrap.
+var valueKinds = map[string]string{
+	".google.protobuf.Timestamp":   "VALUE_TIMESTAMP",
+	".google.protobuf.Duration":    "VALUE_DURATION",
+	".google.protobuf.Struct":      "VALUE_STRUCT",
+	".google.protobuf.Value":       "VALUE_VALUE",
+	".google.protobuf.ListValue":   "VALUE_LIST_VALUE",
+	".google.protobuf.Any":         "VALUE_ANY",
+	".google.protobuf.FieldMask":   "VALUE_FIELD_MASK",
+	".google.protobuf.Empty":       "VALUE_EMPTY",
+	".google.protobuf.DoubleValue": "VALUE_DOUBLE",
+	".google.protobuf.FloatValue":  "VALUE_FLOAT",
+	".google.protobuf.Int64Value":  "VALUE_INT64",
+	".google.protobuf.UInt64Value": "VALUE_UINT64",
+	".google.protobuf.Int32Value":  "VALUE_INT32",
+	".google.protobuf.UInt32Value": "VALUE_UINT32",
+	".google.protobuf.BoolValue":   "VALUE_BOOL",
+	".google.protobuf.StringValue": "VALUE_STRING",
+	".google.protobuf.BytesValue":  "VALUE_BYTES",
+}
+
+// valueKind returns the GDBufUtils::ValueKind constant selecting how GDBufUtils converts the
+// field's value, VALUE_MESSAGE for messages converted by their generated class.
+func valueKind(field *descriptorpb.FieldDescriptorProto) string {
+	var kind string
+	switch field.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		kind = "VALUE_BOOL"
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_SINT32, descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+		kind = "VALUE_INT32"
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT32, descriptorpb.FieldDescriptorProto_TYPE_FIXED32:
+		kind = "VALUE_UINT32"
+	case descriptorpb.FieldDescriptorProto_TYPE_INT64, descriptorpb.FieldDescriptorProto_TYPE_SINT64, descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+		kind = "VALUE_INT64"
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT64, descriptorpb.FieldDescriptorProto_TYPE_FIXED64:
+		kind = "VALUE_UINT64"
+	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT:
+		kind = "VALUE_FLOAT"
+	case descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:
+		kind = "VALUE_DOUBLE"
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		kind = "VALUE_STRING"
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		kind = "VALUE_BYTES"
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		kind = "VALUE_ENUM"
+	default:
+		kind = "VALUE_MESSAGE"
+		if wktKind, ok := valueKinds[field.GetTypeName()]; ok {
+			kind = wktKind
+		}
+	}
+	return "GDBufUtils::" + kind
+}
+
+// wireType returns the GDBufUtils::WireType suffix the streaming callbacks encode the field's value with: the
+// proto scalar type, the wrapped type for wrappers and MESSAGE for messages, well-known types and map entries.
+func wireType(field *descriptorpb.FieldDescriptorProto) string {
+	if isWrapperType(field.GetTypeName()) {
+		return strings.TrimPrefix(valueKinds[field.GetTypeName()], "VALUE_")
+	}
+	if isMessageField(field) {
+		return "MESSAGE"
+	}
+	return strings.TrimPrefix(field.GetType().String(), "TYPE_")
+}
+
+// godotVariantType returns the godot::Variant::Type constant of a godot type.
+func godotVariantType(godotType string, isCustom bool, isEnum bool) string {
+	if strings.HasPrefix(godotType, "godot::TypedArray<") {
+		return "godot::Variant::ARRAY"
+	}
+	if strings.HasPrefix(godotType, "godot::TypedDictionary<") {
+		return "godot::Variant::DICTIONARY"
+	}
+	if isEnum {
+		return "godot::Variant::INT"
+	}
+	if isCustom {
+		return "godot::Variant::OBJECT"
+	}
+	switch godotType {
+	case "bool":
+		return "godot::Variant::BOOL"
+	case "int32_t", "int64_t", "uint32_t", "uint64_t":
+		return "godot::Variant::INT"
+	case "float", "double":
+		return "godot::Variant::FLOAT"
+	case "godot::String":
+		return "godot::Variant::STRING"
+	case "godot::PackedByteArray":
+		return "godot::Variant::PACKED_BYTE_ARRAY"
+	case "godot::PackedStringArray":
+		return "godot::Variant::PACKED_STRING_ARRAY"
+	case "godot::Dictionary":
+		return "godot::Variant::DICTIONARY"
+	case "godot::Array":
+		return "godot::Variant::ARRAY"
+	default:
+		return "godot::Variant::NIL"
+	}
+}
+
+// wrapperGodotTypes maps the wrappers onto the godot type of the value they wrap.
+var wrapperGodotTypes = map[string]string{
+	".google.protobuf.DoubleValue": "double",
+	".google.protobuf.FloatValue":  "float",
+	".google.protobuf.Int64Value":  "int64_t",
+	".google.protobuf.UInt64Value": "uint64_t",
+	".google.protobuf.Int32Value":  "int32_t",
+	".google.protobuf.UInt32Value": "uint32_t",
+	".google.protobuf.BoolValue":   "bool",
+	".google.protobuf.StringValue": "godot::String",
+	".google.protobuf.BytesValue":  "godot::PackedByteArray",
+}
+
+// valueVariantType returns the godot::Variant::Type of a single value of the field. Wrappers are
+// exposed as a Variant but hold the type they wrap, a uint64 policy replaces it.
+func valueVariantType(field *descriptorpb.FieldDescriptorProto, godotType string, isCustom, isEnum bool, policy Uint64Policy) string {
+	if wrapped, ok := wrapperGodotTypes[field.GetTypeName()]; ok {
+		godotType = wrapped
+		if policy != "" {
+			godotType, _ = uint64PolicyGodotType(policy)
+		}
+	}
+	return godotVariantType(godotType, isCustom, isEnum)
+}
+
+// jsonName returns the lowerCamelCase name of a field in the proto3 JSON mapping, protoc fills
+// json_name in the descriptor but hand written descriptor sets may leave it out.
+func jsonName(field *descriptorpb.FieldDescriptorProto) string {
+	if field.JsonName != nil {
+		return field.GetJsonName()
+	}
+	var name strings.Builder
+	upper := false
+	for _, r := range field.GetName() {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	return name.String()
+}
+
+// textName returns the name of a field in the text format, groups are written with the name of their
+// message type like protoc does, e.g. Settings for `optional group Settings = 1`.
+func textName(field *descriptorpb.FieldDescriptorProto) string {
+	if field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP {
+		typeName := field.GetTypeName()
+		return typeName[strings.LastIndex(typeName, ".")+1:]
+	}
+	return field.GetName()
+}
+
+func resolveGodotType(field *descriptorpb.FieldDescriptorProto, currentProtoPath string, fileToMsgs map[string][]string, fileToEnum map[string][]string, allMessageDescriptors map[string]*descriptorpb.DescriptorProto, typeToClassName map[string]string) (godotType string, godotClassName string, isCustom bool, isEnum bool, srcFile string, err error) {
 	fieldType := *field.GetType().Enum()
 	fullTypeName := field.GetTypeName()
 
 	srcFile = ""
 
 	switch fieldType {
-	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE:
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		// proto2 groups are encoded differently on the wire but are otherwise plain nested messages
 		if strings.HasPrefix(fullTypeName, ".google.protobuf.") {
 			srcFile = "google::protobuf"
 		} else {
@@ -95,7 +285,7 @@ func resolveGodotType(field *descriptorpb.FieldDescriptorProto, currentProtoPath
 		} else {
 			isCustom = true
 			var ok bool
-			godotClassName, ok = typeToGodotName[fullTypeName]
+			godotClassName, ok = typeToClassName[fullTypeName]
 			if !ok {
 				// Fallback or error? Should be there.
 				// Try simple name
@@ -104,8 +294,8 @@ func resolveGodotType(field *descriptorpb.FieldDescriptorProto, currentProtoPath
 				} else {
 					godotClassName = fullTypeName
 				}
+				godotClassName = toPascalCase(godotClassName)
 			}
-			godotClassName = toPascalCase(godotClassName)
 
 			if srcFile == currentProtoPath {
 				godotType = godotClassName
@@ -125,7 +315,7 @@ func resolveGodotType(field *descriptorpb.FieldDescriptorProto, currentProtoPath
 		}
 		isCustom = false
 		isEnum = true
-		godotType = "int32_t" // Bind as int to avoid Godot binding issues with C++ enums
+		godotType = "int32_t" // only the storage is an int, the property is bound with its enum class
 		godotClassName = "int"
 	default:
 		isCustom = false
@@ -139,3 +329,264 @@ func resolveGodotType(field *descriptorpb.FieldDescriptorProto, currentProtoPath
 
 	return godotType, godotClassName, isCustom, isEnum, srcFile, nil
 }
+
+func isUint64Field(field *descriptorpb.FieldDescriptorProto) bool {
+	return field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_UINT64 || field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_FIXED64
+}
+
+// uint64PolicyGodotType returns the godot type a uint64 field is exposed as under the policy.
+func uint64PolicyGodotType(policy Uint64Policy) (godotType string, godotClassName string) {
+	switch policy {
+	case Uint64Clamp:
+		return "int64_t", "int"
+	case Uint64String:
+		return "godot::String", "String"
+	case Uint64Bytes:
+		return "godot::PackedByteArray", "PackedByteArray"
+	default:
+		return "uint64_t", "int"
+	}
+}
+
+// uint64PolicyDefaultValue converts the default of a uint64 field into a C++ expression of the godot
+// type the policy exposes it as. Unlike cppDefaultValue, the String and PackedByteArray policies
+// also spell out the implicit 0 so an unset field never reads back empty.
+func uint64PolicyDefaultValue(field *descriptorpb.FieldDescriptorProto, policy Uint64Policy) (string, error) {
+	var v uint64
+	if field.DefaultValue != nil {
+		var err error
+		if v, err = strconv.ParseUint(field.GetDefaultValue(), 10, 64); err != nil {
+			return "", fmt.Errorf("invalid default value for field %s: %w", field.GetName(), err)
+		}
+	} else if policy != Uint64String && policy != Uint64Bytes {
+		return "", nil
+	}
+	switch policy {
+	case Uint64Clamp:
+		if v > math.MaxInt64 {
+			return "(int64_t)INT64_MAX", nil
+		}
+		return fmt.Sprintf("(int64_t)%dLL", v), nil
+	case Uint64String:
+		return fmt.Sprintf("godot::String(\"%d\")", v), nil
+	case Uint64Bytes:
+		return fmt.Sprintf("GDBufUtils::uint64_to_bytes(%dULL)", v), nil
+	default:
+		return fmt.Sprintf("(uint64_t)%dULL", v), nil
+	}
+}
+
+// cppDefaultValue converts the declared proto2/editions `[default = ...]` of a field into a C++
+// expression of its godot type. An empty string is returned when the field declares no default.
+func cppDefaultValue(field *descriptorpb.FieldDescriptorProto, enumDescriptors map[string]*descriptorpb.EnumDescriptorProto) (string, error) {
+	if field.DefaultValue == nil {
+		return "", nil
+	}
+	defaultValue := field.GetDefaultValue()
+
+	switch field.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		return fmt.Sprintf("godot::String::utf8(%s, %d)", cppStringLiteral([]byte(defaultValue)), len(defaultValue)), nil
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		b, err := unescapeDefaultBytes(defaultValue)
+		if err != nil {
+			return "", fmt.Errorf("invalid default value for bytes field %s: %w", field.GetName(), err)
+		}
+		return fmt.Sprintf("GDBufUtils::bytes_literal(%s, %d)", cppStringLiteral(b), len(b)), nil
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		if defaultValue != "true" && defaultValue != "false" {
+			return "", fmt.Errorf("invalid default value for bool field %s: %s", field.GetName(), defaultValue)
+		}
+		return defaultValue, nil
+	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:
+		cppType := protoGodotTypeMap[field.GetType()]
+		switch defaultValue {
+		case "inf":
+			return fmt.Sprintf("std::numeric_limits<%s>::infinity()", cppType), nil
+		case "-inf":
+			return fmt.Sprintf("-std::numeric_limits<%s>::infinity()", cppType), nil
+		case "nan":
+			return fmt.Sprintf("std::numeric_limits<%s>::quiet_NaN()", cppType), nil
+		}
+		if _, err := strconv.ParseFloat(defaultValue, 64); err != nil {
+			return "", fmt.Errorf("invalid default value for floating point field %s: %w", field.GetName(), err)
+		}
+		return fmt.Sprintf("(%s)%s", cppType, defaultValue), nil
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		enum, ok := enumDescriptors[field.GetTypeName()]
+		if !ok {
+			return "", fmt.Errorf("could not find enum %s for default value of field %s", field.GetTypeName(), field.GetName())
+		}
+		for _, value := range enum.GetValue() {
+			if value.GetName() == defaultValue {
+				return fmt.Sprintf("(int32_t)%d", value.GetNumber()), nil
+			}
+		}
+		return "", fmt.Errorf("enum %s has no value %s for default value of field %s", field.GetTypeName(), defaultValue, field.GetName())
+	}
+
+	cppType, ok := protoGodotTypeMap[field.GetType()]
+	if !ok {
+		return "", fmt.Errorf("default values are not supported for field %s of type %s", field.GetName(), field.GetType())
+	}
+	if strings.HasPrefix(cppType, "uint") {
+		v, err := strconv.ParseUint(defaultValue, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid default value for field %s: %w", field.GetName(), err)
+		}
+		return fmt.Sprintf("(%s)%dULL", cppType, v), nil
+	}
+	v, err := strconv.ParseInt(defaultValue, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid default value for field %s: %w", field.GetName(), err)
+	}
+	if v == math.MinInt64 {
+		// the positive literal would not fit into a long long
+		return fmt.Sprintf("(%s)INT64_MIN", cppType), nil
+	}
+	return fmt.Sprintf("(%s)%dLL", cppType, v), nil
+}
+
+// cppStringLiteral quotes the bytes as a C++ string literal. Everything outside of printable
+// ASCII is octal escaped so the literal is valid regardless of source encoding.
+func cppStringLiteral(b []byte) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, c := range b {
+		switch {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c >= 0x20 && c < 0x7f && c != '?': // avoid accidental trigraphs
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "\\%03o", c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// unescapeDefaultBytes reverses the C style escaping protoc applies to default values of bytes fields.
+func unescapeDefaultBytes(s string) ([]byte, error) {
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out = append(out, s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return nil, fmt.Errorf("trailing backslash in %q", s)
+		}
+		switch c := s[i]; c {
+		case 'a':
+			out = append(out, '\a')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'v':
+			out = append(out, '\v')
+		case '\\', '\'', '"', '?':
+			out = append(out, c)
+		case 'x', 'X':
+			j := i + 1
+			for j < len(s) && j < i+3 && isHexDigit(s[j]) {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("invalid hex escape in %q", s)
+			}
+			v, _ := strconv.ParseUint(s[i+1:j], 16, 8)
+			out = append(out, byte(v))
+			i = j - 1
+		default:
+			if c < '0' || c > '7' {
+				return nil, fmt.Errorf("invalid escape \\%c in %q", c, s)
+			}
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			v, err := strconv.ParseUint(s[i:j], 8, 16)
+			if err != nil || v > 0xff {
+				return nil, fmt.Errorf("invalid octal escape in %q", s)
+			}
+			out = append(out, byte(v))
+			i = j - 1
+		}
+	}
+	return out, nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// enumHint builds the PROPERTY_HINT_ENUM hint string of an enum, listing every value
+// with its number so that non contiguous enums map onto the right integers.
+func enumHint(enum *descriptorpb.EnumDescriptorProto) string {
+	values := make([]string, 0, len(enum.GetValue()))
+	for _, value := range enum.GetValue() {
+		values = append(values, fmt.Sprintf("%s:%d", value.GetName(), value.GetNumber()))
+	}
+	return strings.Join(values, ",")
+}
+
+// godotBuiltinTypeName returns the GDScript name of a builtin C++ type, Variant when there is none.
+func godotBuiltinTypeName(godotType string) string {
+	switch godotType {
+	case "bool":
+		return "bool"
+	case "int32_t", "int64_t", "uint32_t", "uint64_t":
+		return "int"
+	case "float", "double":
+		return "float"
+	case "godot::String":
+		return "String"
+	case "godot::PackedByteArray":
+		return "PackedByteArray"
+	case "godot::PackedStringArray":
+		return "PackedStringArray"
+	case "godot::Dictionary":
+		return "Dictionary"
+	case "godot::Array":
+		return "Array"
+	default:
+		return "Variant"
+	}
+}
+
+// godotElementDocType returns the class reference name of a typed array or dictionary element,
+// message classes drop their C++ namespace.
+func godotElementDocType(elementType string) string {
+	if name := godotBuiltinTypeName(elementType); name != "Variant" {
+		return name
+	}
+	parts := strings.Split(elementType, "::")
+	return parts[len(parts)-1]
+}
+
+// typedElement returns the element type of a repeated field or map value for TypedArray and
+// TypedDictionary, with its PROPERTY_HINT_ARRAY_TYPE or PROPERTY_HINT_DICTIONARY_TYPE hint string.
+// Variant elements (Value, Empty and the wrappers) have no element type, those fields stay an
+// untyped Array or Dictionary.
+func typedElement(godotType, godotClassName string, isCustom, isEnum bool, enumHint string) (elementType string, hint string) {
+	switch {
+	case isCustom:
+		return godotType, godotClassName
+	case isEnum:
+		// "<Variant::INT>/<PROPERTY_HINT_ENUM>:<values>" edits every element with the enum drop-down
+		return godotType, "2/2:" + enumHint
+	case godotType == "godot::Variant":
+		return "", ""
+	}
+	return godotType, godotBuiltinTypeName(godotType)
+}
@@ -3,6 +3,7 @@ package codegen
 import (
 	"testing"
 
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -32,6 +33,76 @@ func TestResolveGodotType(t *testing.T) {
 			wantType:     "int32_t",
 			wantIsCustom: false,
 		},
+		{
+			name:     "Primitive Int64",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_INT64)},
+			wantType: "int64_t",
+		},
+		{
+			name:     "Primitive Uint32",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_UINT32)},
+			wantType: "uint32_t",
+		},
+		{
+			name:     "Primitive Uint64",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_UINT64)},
+			wantType: "uint64_t",
+		},
+		{
+			name:     "Primitive Sint32",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_SINT32)},
+			wantType: "int32_t",
+		},
+		{
+			name:     "Primitive Sint64",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_SINT64)},
+			wantType: "int64_t",
+		},
+		{
+			name:     "Primitive Fixed32",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_FIXED32)},
+			wantType: "uint32_t",
+		},
+		{
+			name:     "Primitive Fixed64",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_FIXED64)},
+			wantType: "uint64_t",
+		},
+		{
+			name:     "Primitive Sfixed32",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_SFIXED32)},
+			wantType: "int32_t",
+		},
+		{
+			name:     "Primitive Sfixed64",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_SFIXED64)},
+			wantType: "int64_t",
+		},
+		{
+			name:     "Primitive Float",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_FLOAT)},
+			wantType: "float",
+		},
+		{
+			name:     "Primitive Double",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_DOUBLE)},
+			wantType: "double",
+		},
+		{
+			name:     "Primitive Bool",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_BOOL)},
+			wantType: "bool",
+		},
+		{
+			name:     "Primitive String",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_STRING)},
+			wantType: "godot::String",
+		},
+		{
+			name:     "Primitive Bytes",
+			field:    &descriptorpb.FieldDescriptorProto{Type: typeEnum(descriptorpb.FieldDescriptorProto_TYPE_BYTES)},
+			wantType: "godot::PackedByteArray",
+		},
 		{
 			name: "WKT Timestamp",
 			field: &descriptorpb.FieldDescriptorProto{
@@ -50,6 +121,15 @@ func TestResolveGodotType(t *testing.T) {
 			wantType:     "godot::Dictionary",
 			wantIsCustom: false,
 		},
+		{
+			name: "WKT Wrapper",
+			field: &descriptorpb.FieldDescriptorProto{
+				Type:     typeEnum(descriptorpb.FieldDescriptorProto_TYPE_MESSAGE),
+				TypeName: s(".google.protobuf.UInt64Value"),
+			},
+			wantType:     "godot::Variant",
+			wantIsCustom: false,
+		},
 		{
 			name: "Custom Message Same File",
 			field: &descriptorpb.FieldDescriptorProto{
@@ -120,3 +200,283 @@ func TestResolveGodotType(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveGodotTypeGroup(t *testing.T) {
+	field := &descriptorpb.FieldDescriptorProto{
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_GROUP.Enum(),
+		TypeName: proto.String(".legacy.Outer.Result"),
+	}
+	fileToMsgs := map[string][]string{"legacy.proto": {".legacy.Outer", ".legacy.Outer.Result"}}
+	typeToClassName := map[string]string{".legacy.Outer.Result": "OuterResult"}
+
+	gotType, _, gotIsCustom, _, _, err := resolveGodotType(field, "legacy.proto", fileToMsgs, nil, nil, typeToClassName)
+	if err != nil {
+		t.Fatalf("resolveGodotType() error = %v", err)
+	}
+	if gotType != "OuterResult" || !gotIsCustom {
+		t.Errorf("resolveGodotType() = %v, %v, want OuterResult, true", gotType, gotIsCustom)
+	}
+}
+
+func TestCppDefaultValue(t *testing.T) {
+	enums := map[string]*descriptorpb.EnumDescriptorProto{
+		".Color": {
+			Name: proto.String("Color"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("RED"), Number: proto.Int32(1)},
+				{Name: proto.String("GREEN"), Number: proto.Int32(2)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		fieldType    descriptorpb.FieldDescriptorProto_Type
+		typeName     string
+		defaultValue *string
+		want         string
+		wantErr      bool
+	}{
+		{name: "No Default", fieldType: descriptorpb.FieldDescriptorProto_TYPE_INT32, want: ""},
+		{name: "Int32", fieldType: descriptorpb.FieldDescriptorProto_TYPE_INT32, defaultValue: proto.String("-42"), want: "(int32_t)-42LL"},
+		{name: "Int64 Min", fieldType: descriptorpb.FieldDescriptorProto_TYPE_INT64, defaultValue: proto.String("-9223372036854775808"), want: "(int64_t)INT64_MIN"},
+		{name: "Uint64 Max", fieldType: descriptorpb.FieldDescriptorProto_TYPE_UINT64, defaultValue: proto.String("18446744073709551615"), want: "(uint64_t)18446744073709551615ULL"},
+		{name: "Double", fieldType: descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, defaultValue: proto.String("1.5"), want: "(double)1.5"},
+		{name: "Float Inf", fieldType: descriptorpb.FieldDescriptorProto_TYPE_FLOAT, defaultValue: proto.String("-inf"), want: "-std::numeric_limits<float>::infinity()"},
+		{name: "Double NaN", fieldType: descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, defaultValue: proto.String("nan"), want: "std::numeric_limits<double>::quiet_NaN()"},
+		{name: "Bool", fieldType: descriptorpb.FieldDescriptorProto_TYPE_BOOL, defaultValue: proto.String("true"), want: "true"},
+		{name: "String", fieldType: descriptorpb.FieldDescriptorProto_TYPE_STRING, defaultValue: proto.String("a\"b\n"), want: `godot::String::utf8("a\"b\012", 4)`},
+		{name: "Bytes", fieldType: descriptorpb.FieldDescriptorProto_TYPE_BYTES, defaultValue: proto.String(`\001\x02z\'`), want: `GDBufUtils::bytes_literal("\001\002z'", 4)`},
+		{name: "Enum", fieldType: descriptorpb.FieldDescriptorProto_TYPE_ENUM, typeName: ".Color", defaultValue: proto.String("GREEN"), want: "(int32_t)2"},
+		{name: "Unknown Enum Value", fieldType: descriptorpb.FieldDescriptorProto_TYPE_ENUM, typeName: ".Color", defaultValue: proto.String("BLUE"), wantErr: true},
+		{name: "Invalid Int", fieldType: descriptorpb.FieldDescriptorProto_TYPE_INT32, defaultValue: proto.String("abc"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &descriptorpb.FieldDescriptorProto{
+				Name:         proto.String("field"),
+				Type:         tt.fieldType.Enum(),
+				DefaultValue: tt.defaultValue,
+			}
+			if tt.typeName != "" {
+				field.TypeName = proto.String(tt.typeName)
+			}
+			got, err := cppDefaultValue(field, enums)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cppDefaultValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("cppDefaultValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnumHint(t *testing.T) {
+	enum := &descriptorpb.EnumDescriptorProto{
+		Name: proto.String("Sparse"),
+		Value: []*descriptorpb.EnumValueDescriptorProto{
+			{Name: proto.String("SPARSE_UNSPECIFIED"), Number: proto.Int32(0)},
+			{Name: proto.String("SPARSE_TEN"), Number: proto.Int32(10)},
+			{Name: proto.String("SPARSE_NEGATIVE"), Number: proto.Int32(-1)},
+		},
+	}
+	want := "SPARSE_UNSPECIFIED:0,SPARSE_TEN:10,SPARSE_NEGATIVE:-1"
+	if got := enumHint(enum); got != want {
+		t.Errorf("enumHint() = %v, want %v", got, want)
+	}
+}
+
+func TestUint64PolicyDefaultValue(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       Uint64Policy
+		defaultValue *string
+		want         string
+	}{
+		{name: "Clamp Max", policy: Uint64Clamp, defaultValue: proto.String("18446744073709551615"), want: "(int64_t)INT64_MAX"},
+		{name: "Clamp Small", policy: Uint64Clamp, defaultValue: proto.String("7"), want: "(int64_t)7LL"},
+		{name: "Clamp No Default", policy: Uint64Clamp, want: ""},
+		{name: "String", policy: Uint64String, defaultValue: proto.String("18446744073709551615"), want: `godot::String("18446744073709551615")`},
+		{name: "String No Default", policy: Uint64String, want: `godot::String("0")`},
+		{name: "Bytes", policy: Uint64Bytes, defaultValue: proto.String("1"), want: "GDBufUtils::uint64_to_bytes(1ULL)"},
+		{name: "Bytes No Default", policy: Uint64Bytes, want: "GDBufUtils::uint64_to_bytes(0ULL)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &descriptorpb.FieldDescriptorProto{
+				Name:         proto.String("field"),
+				Type:         descriptorpb.FieldDescriptorProto_TYPE_UINT64.Enum(),
+				DefaultValue: tt.defaultValue,
+			}
+			got, err := uint64PolicyDefaultValue(field, tt.policy)
+			if err != nil {
+				t.Fatalf("uint64PolicyDefaultValue() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("uint64PolicyDefaultValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypedElement(t *testing.T) {
+	tests := []struct {
+		name            string
+		godotType       string
+		godotClassName  string
+		isCustom        bool
+		isEnum          bool
+		wantElementType string
+		wantHint        string
+	}{
+		{name: "Int", godotType: "int32_t", godotClassName: "int32_t", wantElementType: "int32_t", wantHint: "int"},
+		{name: "String", godotType: "godot::String", godotClassName: "String", wantElementType: "godot::String", wantHint: "String"},
+		{name: "Bytes", godotType: "godot::PackedByteArray", godotClassName: "PackedByteArray", wantElementType: "godot::PackedByteArray", wantHint: "PackedByteArray"},
+		{name: "Enum", godotType: "int32_t", godotClassName: "int", isEnum: true, wantElementType: "int32_t", wantHint: "2/2:RED:0,GREEN:1"},
+		{name: "Message", godotType: "gdbuf::items::Item", godotClassName: "Item", isCustom: true, wantElementType: "gdbuf::items::Item", wantHint: "Item"},
+		{name: "Struct", godotType: "godot::Dictionary", godotClassName: "Dictionary", wantElementType: "godot::Dictionary", wantHint: "Dictionary"},
+		{name: "Wrapper", godotType: "godot::Variant", godotClassName: "Variant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elementType, hint := typedElement(tt.godotType, tt.godotClassName, tt.isCustom, tt.isEnum, "RED:0,GREEN:1")
+			if elementType != tt.wantElementType || hint != tt.wantHint {
+				t.Errorf("typedElement() = %q, %q, want %q, %q", elementType, hint, tt.wantElementType, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestValueKind(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType descriptorpb.FieldDescriptorProto_Type
+		typeName  string
+		want      string
+	}{
+		{name: "SInt32", fieldType: descriptorpb.FieldDescriptorProto_TYPE_SINT32, want: "GDBufUtils::VALUE_INT32"},
+		{name: "Fixed32", fieldType: descriptorpb.FieldDescriptorProto_TYPE_FIXED32, want: "GDBufUtils::VALUE_UINT32"},
+		{name: "SFixed64", fieldType: descriptorpb.FieldDescriptorProto_TYPE_SFIXED64, want: "GDBufUtils::VALUE_INT64"},
+		{name: "Fixed64", fieldType: descriptorpb.FieldDescriptorProto_TYPE_FIXED64, want: "GDBufUtils::VALUE_UINT64"},
+		{name: "Bytes", fieldType: descriptorpb.FieldDescriptorProto_TYPE_BYTES, want: "GDBufUtils::VALUE_BYTES"},
+		{name: "Enum", fieldType: descriptorpb.FieldDescriptorProto_TYPE_ENUM, typeName: ".Color", want: "GDBufUtils::VALUE_ENUM"},
+		{name: "Message", fieldType: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, typeName: ".Item", want: "GDBufUtils::VALUE_MESSAGE"},
+		{name: "Group", fieldType: descriptorpb.FieldDescriptorProto_TYPE_GROUP, typeName: ".Item.Group", want: "GDBufUtils::VALUE_MESSAGE"},
+		{name: "Timestamp", fieldType: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, typeName: ".google.protobuf.Timestamp", want: "GDBufUtils::VALUE_TIMESTAMP"},
+		{name: "Wrapper", fieldType: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, typeName: ".google.protobuf.UInt64Value", want: "GDBufUtils::VALUE_UINT64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &descriptorpb.FieldDescriptorProto{Type: tt.fieldType.Enum(), TypeName: proto.String(tt.typeName)}
+			if got := valueKind(field); got != tt.want {
+				t.Errorf("valueKind() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWireType(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType descriptorpb.FieldDescriptorProto_Type
+		typeName  string
+		want      string
+	}{
+		{name: "SInt32", fieldType: descriptorpb.FieldDescriptorProto_TYPE_SINT32, want: "SINT32"},
+		{name: "Fixed64", fieldType: descriptorpb.FieldDescriptorProto_TYPE_FIXED64, want: "FIXED64"},
+		{name: "Enum", fieldType: descriptorpb.FieldDescriptorProto_TYPE_ENUM, typeName: ".Color", want: "ENUM"},
+		{name: "Message", fieldType: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, typeName: ".Item", want: "MESSAGE"},
+		{name: "Group", fieldType: descriptorpb.FieldDescriptorProto_TYPE_GROUP, typeName: ".Item.Group", want: "MESSAGE"},
+		{name: "Struct", fieldType: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, typeName: ".google.protobuf.Struct", want: "MESSAGE"},
+		{name: "Wrapper", fieldType: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, typeName: ".google.protobuf.UInt32Value", want: "UINT32"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &descriptorpb.FieldDescriptorProto{Type: tt.fieldType.Enum(), TypeName: proto.String(tt.typeName)}
+			if got := wireType(field); got != tt.want {
+				t.Errorf("wireType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValueVariantType(t *testing.T) {
+	tests := []struct {
+		name      string
+		typeName  string
+		godotType string
+		isCustom  bool
+		isEnum    bool
+		policy    Uint64Policy
+		want      string
+	}{
+		{name: "Scalar", godotType: "int32_t", want: "godot::Variant::INT"},
+		{name: "Enum", typeName: ".Color", godotType: "Color", isEnum: true, want: "godot::Variant::INT"},
+		{name: "Message", typeName: ".Item", godotType: "gdbuf::item::Item", isCustom: true, want: "godot::Variant::OBJECT"},
+		{name: "Policy", godotType: "godot::String", policy: Uint64String, want: "godot::Variant::STRING"},
+		{name: "Wrapper", typeName: ".google.protobuf.BoolValue", godotType: "godot::Variant", want: "godot::Variant::BOOL"},
+		{name: "WrapperPolicy", typeName: ".google.protobuf.UInt64Value", godotType: "godot::Variant", policy: Uint64Bytes, want: "godot::Variant::PACKED_BYTE_ARRAY"},
+		{name: "Value", typeName: ".google.protobuf.Value", godotType: "godot::Variant", want: "godot::Variant::NIL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &descriptorpb.FieldDescriptorProto{TypeName: proto.String(tt.typeName)}
+			if got := valueVariantType(field, tt.godotType, tt.isCustom, tt.isEnum, tt.policy); got != tt.want {
+				t.Errorf("valueVariantType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONName(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    string
+		jsonName *string
+		want     string
+	}{
+		{name: "Descriptor", field: "user_id", jsonName: proto.String("userId"), want: "userId"},
+		{name: "Custom", field: "user_id", jsonName: proto.String("uid"), want: "uid"},
+		{name: "Derived", field: "user_id", want: "userId"},
+		{name: "Digits", field: "int32_field_2", want: "int32Field2"},
+		{name: "Plain", field: "name", want: "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &descriptorpb.FieldDescriptorProto{Name: proto.String(tt.field), JsonName: tt.jsonName}
+			if got := jsonName(field); got != tt.want {
+				t.Errorf("jsonName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextName(t *testing.T) {
+	group := descriptorpb.FieldDescriptorProto_TYPE_GROUP.Enum()
+	message := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	tests := []struct {
+		name  string
+		field *descriptorpb.FieldDescriptorProto
+		want  string
+	}{
+		{name: "Group", field: &descriptorpb.FieldDescriptorProto{Name: proto.String("settings"), Type: group, TypeName: proto.String(".legacy.LegacyGroupMessage.Settings")}, want: "Settings"},
+		{name: "Message", field: &descriptorpb.FieldDescriptorProto{Name: proto.String("child"), Type: message, TypeName: proto.String(".legacy.Child")}, want: "child"},
+		{name: "Scalar", field: &descriptorpb.FieldDescriptorProto{Name: proto.String("user_id")}, want: "user_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := textName(tt.field); got != tt.want {
+				t.Errorf("textName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package protoc
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// LoadDescriptorSet reads a binary FileDescriptorSet, as produced by
+// `protoc --descriptor_set_out` or `buf build -o`. The set has to be built with
+// imports included so that the nanopb sources of every dependency can be generated.
+func LoadDescriptorSet(descriptorSetPath string) ([]*descriptorpb.FileDescriptorProto, error) {
+	protoDescData, err := os.ReadFile(descriptorSetPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read proto description file: %w", err)
+	}
+
+	var protoFileDescriptorSet descriptorpb.FileDescriptorSet
+	if err = proto.Unmarshal(protoDescData, &protoFileDescriptorSet); err != nil {
+		return nil, fmt.Errorf("could not unmarshal proto description data: %w", err)
+	}
+
+	files := protoFileDescriptorSet.GetFile()
+	if len(files) == 0 {
+		return nil, fmt.Errorf("descriptor set %s contains no files", descriptorSetPath)
+	}
+
+	return files, nil
+}
+
+// CompileNanopbFromDescriptorSet generates the nanopb sources for every file in
+// the descriptor set. Instead of going through protoc, the nanopb generator is
+// invoked directly as a plugin with a CodeGeneratorRequest on stdin.
+func CompileNanopbFromDescriptorSet(logger *slog.Logger, files []*descriptorpb.FileDescriptorProto, generatorDir string) (string, error) {
+	var fileNames []string
+	for _, file := range files {
+		fileNames = append(fileNames, file.GetName())
+	}
+
+	for _, wkt := range wellKnownTypeFiles {
+		if !slices.Contains(fileNames, wkt) {
+			return "", fmt.Errorf("descriptor set is missing well-known type %s, build it with imports included", wkt)
+		}
+	}
+
+	request := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: fileNames,
+		Parameter:      proto.String(nanopbOptions),
+		ProtoFile:      files,
+	}
+	requestData, err := proto.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal nanopb code generator request: %w", err)
+	}
+
+	pluginPath := nanopbPluginPath(logger, generatorDir)
+	compileCppCmd := exec.Command(pluginPath)
+	compileCppCmd.Stdin = bytes.NewReader(requestData)
+
+	var stdout, stderr bytes.Buffer
+	compileCppCmd.Stdout = &stdout
+	compileCppCmd.Stderr = &stderr
+
+	if err := compileCppCmd.Run(); err != nil {
+		return "", fmt.Errorf("could not run nanopb plugin [%s]: %s", pluginPath, stderr.String())
+	}
+
+	var response pluginpb.CodeGeneratorResponse
+	if err := proto.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return "", fmt.Errorf("could not unmarshal nanopb code generator response: %w", err)
+	}
+	if response.Error != nil {
+		return "", fmt.Errorf("nanopb plugin reported error: %s", response.GetError())
+	}
+
+	tempProtocBuildDir, err := os.MkdirTemp("", "gdbuf-build-")
+	if err != nil {
+		return "", fmt.Errorf("could not make temp directory for proto cpp build: %w", err)
+	}
+
+	for _, file := range response.GetFile() {
+		outPath := filepath.Join(tempProtocBuildDir, filepath.FromSlash(file.GetName()))
+		if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+			return "", fmt.Errorf("could not create directory for %s: %w", file.GetName(), err)
+		}
+		if err := os.WriteFile(outPath, []byte(file.GetContent()), 0644); err != nil {
+			return "", fmt.Errorf("could not write nanopb output %s: %w", file.GetName(), err)
+		}
+	}
+	logger.Info("generated nanopb sources from descriptor set", "files", len(response.GetFile()))
+
+	return tempProtocBuildDir, nil
+}
@@ -0,0 +1,52 @@
+package protoc
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestLoadDescriptorSet(t *testing.T) {
+	dir := t.TempDir()
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			{Name: proto.String("game/player.proto"), Package: proto.String("game")},
+		},
+	}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setPath := filepath.Join(dir, "set.binpb")
+	if err := os.WriteFile(setPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := LoadDescriptorSet(setPath)
+	if err != nil {
+		t.Fatalf("LoadDescriptorSet() error = %v", err)
+	}
+	if len(files) != 1 || files[0].GetName() != "game/player.proto" {
+		t.Errorf("LoadDescriptorSet() got %v", files)
+	}
+
+	emptyPath := filepath.Join(dir, "empty.binpb")
+	if err := os.WriteFile(emptyPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDescriptorSet(emptyPath); err == nil {
+		t.Errorf("LoadDescriptorSet() expected error for empty set")
+	}
+
+	// imports were not included, so the well-known types can not be generated
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, err := CompileNanopbFromDescriptorSet(logger, files, dir); err == nil {
+		t.Errorf("CompileNanopbFromDescriptorSet() expected error for missing well-known types")
+	}
+}
@@ -0,0 +1,113 @@
+package protoc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/protoutil"
+	"github.com/bufbuild/protocompile/wellknownimports"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ProtoParser is the builtin, pure Go alternative to ProtoCompiler. It parses
+// the proto files itself and ships the sources of the well-known types, so no
+// protoc installation is needed.
+type ProtoParser struct {
+	logger *slog.Logger
+}
+
+func NewProtoParser(logger *slog.Logger) *ProtoParser {
+	return &ProtoParser{
+		logger: logger,
+	}
+}
+
+// BuildDescriptorSet parses the proto files in the directory, resolving imports
+// the same way ProtoCompiler does. Unlike ProtoCompiler the result includes all
+// imports and the well-known types, in dependency order, so it can be handed to
+// CompileNanopbFromDescriptorSet as is.
+func (p *ProtoParser) BuildDescriptorSet(protoFilesDirPath string, includeDirs []string) ([]*descriptorpb.FileDescriptorProto, error) {
+	protoFilePaths, err := getProtoFilesInDir(protoFilesDirPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get proto files from %s: %w", protoFilesDirPath, err)
+	}
+
+	importPaths := includeDirs
+	if len(importPaths) == 0 {
+		importPaths = []string{".", protoFilesDirPath}
+	}
+
+	var fileNames []string
+	for _, protoFilePath := range protoFilePaths {
+		fileName, err := importName(protoFilePath, importPaths)
+		if err != nil {
+			return nil, err
+		}
+		fileNames = append(fileNames, fileName)
+	}
+	for _, wkt := range wellKnownTypeFiles {
+		if !slices.Contains(fileNames, wkt) {
+			fileNames = append(fileNames, wkt)
+		}
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: wellknownimports.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: importPaths,
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+
+	compiledFiles, err := compiler.Compile(context.Background(), fileNames...)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse proto files: %w", err)
+	}
+
+	var descriptorSet []*descriptorpb.FileDescriptorProto
+	seen := make(map[string]bool)
+	var addFile func(file protoreflect.FileDescriptor)
+	addFile = func(file protoreflect.FileDescriptor) {
+		if seen[file.Path()] {
+			return
+		}
+		seen[file.Path()] = true
+		imports := file.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			addFile(imports.Get(i).FileDescriptor)
+		}
+		descriptorSet = append(descriptorSet, protoutil.ProtoFromFileDescriptor(file))
+	}
+	for _, file := range compiledFiles {
+		addFile(file)
+	}
+	p.logger.Info("parsed proto files", "files", len(protoFilePaths), "total", len(descriptorSet))
+
+	return descriptorSet, nil
+}
+
+// importName returns the name protoc would give the file: its path relative to
+// the first import path that contains it.
+func importName(protoFilePath string, importPaths []string) (string, error) {
+	absFilePath, err := filepath.Abs(protoFilePath)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve path %s: %w", protoFilePath, err)
+	}
+	for _, importPath := range importPaths {
+		absImportPath, err := filepath.Abs(importPath)
+		if err != nil {
+			return "", fmt.Errorf("could not resolve include path %s: %w", importPath, err)
+		}
+		relPath, err := filepath.Rel(absImportPath, absFilePath)
+		if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.ToSlash(relPath), nil
+	}
+	return "", fmt.Errorf("proto file %s is not inside any include directory %v", protoFilePath, importPaths)
+}
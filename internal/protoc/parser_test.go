@@ -0,0 +1,51 @@
+package protoc
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestProtoParserBuildDescriptorSet(t *testing.T) {
+	parser := NewProtoParser(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	files, err := parser.BuildDescriptorSet("../../test/proto", []string{"../.."})
+	if err != nil {
+		t.Fatalf("BuildDescriptorSet() error = %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, file.GetName())
+	}
+
+	for _, want := range append([]string{
+		"test/proto/gdbuf_test.proto",
+		"test/proto/dependency.proto",
+		"test/proto/nested/deeply/nested.proto",
+	}, wellKnownTypeFiles...) {
+		if !slices.Contains(names, want) {
+			t.Errorf("BuildDescriptorSet() missing file %s, got %v", want, names)
+		}
+	}
+
+	// dependencies have to come before the files importing them
+	if slices.Index(names, "test/proto/dependency.proto") > slices.Index(names, "test/proto/gdbuf_test.proto") {
+		t.Errorf("BuildDescriptorSet() files not in dependency order: %v", names)
+	}
+
+	for _, file := range files {
+		if file.GetName() == "test/proto/gdbuf_test.proto" && len(file.GetSourceCodeInfo().GetLocation()) == 0 {
+			t.Errorf("BuildDescriptorSet() missing source info for %s", file.GetName())
+		}
+	}
+}
+
+func TestProtoParserBuildDescriptorSetOutsideInclude(t *testing.T) {
+	parser := NewProtoParser(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if _, err := parser.BuildDescriptorSet("../../test/proto", []string{t.TempDir()}); err == nil {
+		t.Errorf("BuildDescriptorSet() expected error for proto files outside include directories")
+	}
+}
@@ -16,6 +16,22 @@ import (
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
+// wellKnownTypeFiles are always compiled with nanopb, the shared helpers in
+// messages.h depend on them whether the user protos import them or not.
+var wellKnownTypeFiles = []string{
+	"google/protobuf/any.proto",
+	"google/protobuf/duration.proto",
+	"google/protobuf/struct.proto",
+	"google/protobuf/timestamp.proto",
+	"google/protobuf/wrappers.proto",
+	"google/protobuf/field_mask.proto",
+	"google/protobuf/empty.proto",
+}
+
+// nanopbOptions are passed to the nanopb generator.
+// FT_POINTER is used for strings/arrays to use malloc/free instead of static buffers/callbacks
+const nanopbOptions = "-s type:FT_POINTER"
+
 type ProtoCompiler struct {
 	logger          *slog.Logger
 	protobufVersion string
@@ -96,19 +112,11 @@ func (c *ProtoCompiler) CompileNanopb(protoFilesDirPath string, includeDirs []st
 		return "", fmt.Errorf("could not get proto files from %s: %w", protoFilesDirPath, err)
 	}
 
-	pluginName := "protoc-gen-nanopb"
-	if runtime.GOOS == "windows" {
-		pluginName += ".bat"
-	}
-	pluginPath := filepath.Join(generatorDir, pluginName)
-	if err := os.Chmod(pluginPath, 0755); err != nil {
-		c.logger.Warn("could not chmod plugin", "path", pluginPath, "err", err)
-	}
+	pluginPath := nanopbPluginPath(c.logger, generatorDir)
 
-	// Use FT_POINTER for strings/arrays to use malloc/free instead of static buffers/callbacks
 	args := []string{
 		fmt.Sprintf("--plugin=protoc-gen-nanopb=%s", pluginPath),
-		"--nanopb_opt=-s type:FT_POINTER",
+		fmt.Sprintf("--nanopb_opt=%s", nanopbOptions),
 		fmt.Sprintf("--nanopb_out=%s", tempProtocBuildDir),
 	}
 
@@ -124,16 +132,7 @@ func (c *ProtoCompiler) CompileNanopb(protoFilesDirPath string, includeDirs []st
 	args = append(args, protoFilePaths...)
 
 	// Add Well-Known Types (WKTs) to ensure they are compiled with Nanopb
-	wkts := []string{
-		"google/protobuf/any.proto",
-		"google/protobuf/duration.proto",
-		"google/protobuf/struct.proto",
-		"google/protobuf/timestamp.proto",
-		"google/protobuf/wrappers.proto",
-		"google/protobuf/field_mask.proto",
-		"google/protobuf/empty.proto",
-	}
-	args = append(args, wkts...)
+	args = append(args, wellKnownTypeFiles...)
 
 	compileCppCmd := exec.Command("protoc", args...)
 
@@ -148,6 +147,18 @@ func (c *ProtoCompiler) CompileNanopb(protoFilesDirPath string, includeDirs []st
 	return tempProtocBuildDir, nil
 }
 
+func nanopbPluginPath(logger *slog.Logger, generatorDir string) string {
+	pluginName := "protoc-gen-nanopb"
+	if runtime.GOOS == "windows" {
+		pluginName += ".bat"
+	}
+	pluginPath := filepath.Join(generatorDir, pluginName)
+	if err := os.Chmod(pluginPath, 0755); err != nil {
+		logger.Warn("could not chmod plugin", "path", pluginPath, "err", err)
+	}
+	return pluginPath
+}
+
 func getProtocExecutableVersion() (string, error) {
 	protoVersionCmdOut, err := exec.Command("protoc", "--version").Output()
 	if err != nil {
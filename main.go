@@ -13,6 +13,7 @@ import (
 	"github.com/LJ-Software/gdbuf/internal/codegen"
 	"github.com/LJ-Software/gdbuf/internal/gdextension"
 	"github.com/LJ-Software/gdbuf/internal/protoc"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 type arrayFlags []string
@@ -35,24 +36,51 @@ func main() {
 	var includeDirs arrayFlags
 	flag.Var(&includeDirs, "include", "include directories for proto files")
 	protoInputDirPtr := flag.String("proto", "", "path to proto definition files")
+	compilerPtr := flag.String("compiler", "builtin", "proto compiler backend (builtin, protoc)")
+	descriptorSetPtr := flag.String("descriptor-set", "", "path to a precompiled FileDescriptorSet (built with imports included), used instead of --proto")
 	cppOutputDirPtr := flag.String("genout", ".", "generated proto c++ code output path")
 	extensionNamePtr := flag.String("name", "gdbufgen", "name of the generated gdextension")
 	extensionArtifactOutputDirPtr := flag.String("out", "./out", "output directory location of the generated gdextension")
 	generateOnlyPtr := flag.Bool("generate-only", false, "only generate c++ code, do not compile gdextension")
 	platformPtr := flag.String("platform", "", "target platform (linux, windows, web, android)")
+	classNamingPtr := flag.String("class-naming", "bare", "derive message class names from the bare message name or prefix the proto package (bare, package)")
+	classNamesPtr := flag.String("class-names", "", "path to a JSON file mapping fully qualified message names to class names, wins over --class-naming")
+	uint64PolicyPtr := flag.String("uint64-policy", "wrap", "expose uint64/fixed64 values above INT64_MAX wrapped, clamped, as a String or as a PackedByteArray (wrap, clamp, string, bytes)")
+	timeTypePtr := flag.String("time-type", "native", "expose Timestamp/Duration as int milliseconds and float seconds, or as the nanosecond precise ProtoTimestamp/ProtoDuration resources (native, resource)")
+	enumScopePtr := flag.String("enum-scope", "package", "generate one enums class per proto package or per proto file (package, file)")
+	legacyNestedConversionPtr := flag.Bool("legacy-nested-conversion", false, "convert nested messages through a serialized bytes round trip like older versions, only used as the benchmark baseline")
 
 	flag.Parse()
 
-	if len(*protoInputDirPtr) == 0 {
-		logger.Error("required argument --proto not given")
+	if len(*protoInputDirPtr) == 0 && len(*descriptorSetPtr) == 0 {
+		logger.Error("required argument --proto or --descriptor-set not given")
 		os.Exit(1)
 	}
 
-	if err := checkPath(*protoInputDirPtr, true); err != nil {
-		logger.Error("invalid path for proto files", "err", err)
+	if len(*protoInputDirPtr) > 0 && len(*descriptorSetPtr) > 0 {
+		logger.Error("arguments --proto and --descriptor-set are mutually exclusive")
 		os.Exit(1)
 	}
 
+	if *compilerPtr != "builtin" && *compilerPtr != "protoc" {
+		logger.Error("invalid value for --compiler, expected builtin or protoc", "compiler", *compilerPtr)
+		os.Exit(1)
+	}
+
+	if len(*protoInputDirPtr) > 0 {
+		if err := checkPath(*protoInputDirPtr, true); err != nil {
+			logger.Error("invalid path for proto files", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	if len(*descriptorSetPtr) > 0 {
+		if err := checkPath(*descriptorSetPtr, false); err != nil {
+			logger.Error("invalid path for descriptor set", "err", err)
+			os.Exit(1)
+		}
+	}
+
 	for _, includeDir := range includeDirs {
 		if err := checkPath(includeDir, true); err != nil {
 			logger.Error("invalid path for include directory", "dir", includeDir, "err", err)
@@ -84,25 +112,73 @@ func main() {
 		os.Exit(1)
 	}
 
-	protoc, err := protoc.NewProtoCompiler(logger.WithGroup("protoc"))
-	if err != nil {
-		logger.Error("could not create new proto compiler", "err", err)
-		os.Exit(1)
-	}
+	var descriptorSet []*descriptorpb.FileDescriptorProto
+	var compiledProtoCppTempDirPath string
+	var protobufVersion string
+	if len(*descriptorSetPtr) > 0 {
+		descriptorSet, err = protoc.LoadDescriptorSet(*descriptorSetPtr)
+		if err != nil {
+			logger.Error("could not load descriptor set", "err", err)
+			os.Exit(1)
+		}
 
-	descriptorSet, err := protoc.BuildDescriptorSet(*protoInputDirPtr, includeDirs)
-	if err != nil {
-		logger.Error("could not build descriptor set for protobuf definitions", "err", err)
-		os.Exit(1)
+		compiledProtoCppTempDirPath, err = protoc.CompileNanopbFromDescriptorSet(logger.WithGroup("protoc"), descriptorSet, genTmpDir)
+		if err != nil {
+			logger.Error("could not compile proto cpp (nanopb)", "err", err)
+			os.Exit(1)
+		}
+	} else if *compilerPtr == "builtin" {
+		protoParser := protoc.NewProtoParser(logger.WithGroup("parser"))
+		descriptorSet, err = protoParser.BuildDescriptorSet(*protoInputDirPtr, includeDirs)
+		if err != nil {
+			logger.Error("could not build descriptor set for protobuf definitions", "err", err)
+			os.Exit(1)
+		}
+
+		compiledProtoCppTempDirPath, err = protoc.CompileNanopbFromDescriptorSet(logger.WithGroup("protoc"), descriptorSet, genTmpDir)
+		if err != nil {
+			logger.Error("could not compile proto cpp (nanopb)", "err", err)
+			os.Exit(1)
+		}
+	} else {
+		protoCompiler, err := protoc.NewProtoCompiler(logger.WithGroup("protoc"))
+		if err != nil {
+			logger.Error("could not create new proto compiler", "err", err)
+			os.Exit(1)
+		}
+		protobufVersion = protoCompiler.GetVersion()
+
+		descriptorSet, err = protoCompiler.BuildDescriptorSet(*protoInputDirPtr, includeDirs)
+		if err != nil {
+			logger.Error("could not build descriptor set for protobuf definitions", "err", err)
+			os.Exit(1)
+		}
+
+		compiledProtoCppTempDirPath, err = protoCompiler.CompileNanopb(*protoInputDirPtr, includeDirs, genTmpDir)
+		if err != nil {
+			logger.Error("could not compile proto cpp (nanopb)", "err", err)
+			os.Exit(1)
+		}
 	}
 
-	compiledProtoCppTempDirPath, err := protoc.CompileNanopb(*protoInputDirPtr, includeDirs, genTmpDir)
-	if err != nil {
-		logger.Error("could not compile proto cpp (nanopb)", "err", err)
-		os.Exit(1)
+	var classNames map[string]string
+	if len(*classNamesPtr) > 0 {
+		classNames, err = codegen.LoadClassNames(*classNamesPtr)
+		if err != nil {
+			logger.Error("could not load class name mapping", "err", err)
+			os.Exit(1)
+		}
 	}
 
-	codeGenerator, err := codegen.NewCodeGenerator(logger, *cppOutputDirPtr, *extensionNamePtr, protoc.GetVersion())
+	codeGenerator, err := codegen.NewCodeGenerator(logger, *cppOutputDirPtr, *extensionNamePtr, protobufVersion, codegen.Options{
+		EnumScope:    codegen.EnumScope(*enumScopePtr),
+		ClassNaming:  codegen.ClassNaming(*classNamingPtr),
+		ClassNames:   classNames,
+		Uint64Policy: codegen.Uint64Policy(*uint64PolicyPtr),
+		TimeType:     codegen.TimeType(*timeTypePtr),
+
+		LegacyNestedConversion: *legacyNestedConversionPtr,
+	})
 	if err != nil {
 		logger.Error("could not create new code generator", "err", err)
 		os.Exit(1)
@@ -0,0 +1,99 @@
+extends SceneTree
+
+# Throughput of to_byte_array() and from_byte_array() on message trees, with
+# nested messages converted straight from and to their nanopb structs.
+#
+# make bench-godot first runs this against a build generated with
+# --legacy-nested-conversion and passes --save-baseline, the second run against
+# the regular build prints those numbers next to its own.
+
+const ITERATIONS = 200
+const BASELINE_PATH = "user://benchmark_baseline.json"
+
+func _init():
+	print("Running gdbuf benchmarks...")
+
+	var save_baseline = "--save-baseline" in OS.get_cmdline_user_args()
+	var baseline = {} if save_baseline else load_baseline()
+
+	var results = {}
+	results["deep"] = bench_tree("deep", build_chain(64), baseline.get("deep", {}))
+	results["wide"] = bench_tree("wide", build_wide(4, 4), baseline.get("wide", {}))
+	results["network tick"] = bench_tree("network tick", build_wide(2, 6), baseline.get("network tick", {}))
+
+	if save_baseline:
+		var file = FileAccess.open(BASELINE_PATH, FileAccess.WRITE)
+		file.store_string(JSON.stringify(results))
+		print("Saved baseline to " + ProjectSettings.globalize_path(BASELINE_PATH))
+
+	quit(0)
+
+func load_baseline():
+	if not FileAccess.file_exists(BASELINE_PATH):
+		print("No baseline at " + ProjectSettings.globalize_path(BASELINE_PATH) + ", run make bench-godot to compare")
+		return {}
+	var baseline = JSON.parse_string(FileAccess.get_file_as_string(BASELINE_PATH))
+	return baseline if baseline is Dictionary else {}
+
+func build_chain(depth):
+	var root = RecursiveMessage.new()
+	root.name = "node 0"
+	var current = root
+	for i in range(1, depth):
+		var child = RecursiveMessage.new()
+		child.name = "node " + str(i)
+		var children: Array[RecursiveMessage] = [child]
+		current.children = children
+		current = child
+	return root
+
+func build_wide(fan_out, depth):
+	var node = RecursiveMessage.new()
+	node.name = "depth " + str(depth)
+	if depth > 1:
+		var children: Array[RecursiveMessage] = []
+		for i in range(fan_out):
+			children.append(build_wide(fan_out, depth - 1))
+		node.children = children
+	return node
+
+func count_nodes(node):
+	var count = 1
+	for child in node.children:
+		count += count_nodes(child)
+	return count
+
+func bench_tree(label, root, baseline):
+	var bytes = root.to_byte_array()
+
+	var start = Time.get_ticks_usec()
+	for i in range(ITERATIONS):
+		root.to_byte_array()
+	var encode_usec = Time.get_ticks_usec() - start
+
+	start = Time.get_ticks_usec()
+	for i in range(ITERATIONS):
+		RecursiveMessage.new().from_byte_array(bytes)
+	var decode_usec = Time.get_ticks_usec() - start
+
+	start = Time.get_ticks_usec()
+	for i in range(ITERATIONS):
+		RecursiveMessage.new().from_byte_array(root.to_byte_array())
+	var round_trip_usec = Time.get_ticks_usec() - start
+
+	var results = {
+		"encode": per_second(encode_usec),
+		"decode": per_second(decode_usec),
+		"encode + decode": per_second(round_trip_usec),
+	}
+
+	print("%s: %d nodes, %d bytes" % [label, count_nodes(root), bytes.size()])
+	for name in results:
+		if baseline.has(name):
+			print("  %-16s %8.1f msg/s (baseline %8.1f msg/s, %.1fx)" % [name, results[name], baseline[name], results[name] / max(baseline[name], 0.001)])
+		else:
+			print("  %-16s %8.1f msg/s" % [name, results[name]])
+	return results
+
+func per_second(usec):
+	return ITERATIONS * 1000000.0 / max(usec, 1)
@@ -13,6 +13,25 @@ func _init():
 	test_nested_message()
 	test_enums()
 	test_to_string()
+	test_field_presence()
+	test_proto2_defaults()
+	test_required_fields()
+	test_groups()
+	test_struct_value()
+	test_any()
+	test_field_mask()
+	test_unsigned_integers()
+	test_wrappers()
+	test_typed_arrays()
+	test_typed_dictionaries()
+	test_map_values()
+	test_deep_nesting()
+	test_streaming()
+	test_json()
+	test_dictionary()
+	test_text_format()
+	test_clone_equals_hash()
+	test_merge()
 
 	if tests_failed == 0:
 		print("ALL TESTS PASSED")
@@ -183,6 +202,898 @@ func test_nested_message():
 
 func test_enums():
 	print("--- test_enums ---")
-	# BasicTestEnum is not exposed as a class/constants, checking values manually
-	pass
+	assert_eq(gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_TWO, 2, "Enum constant value")
+	assert_eq(gdbufgenLegacyEnums.LegacyColor.LEGACY_COLOR_BLUE, 3, "Enum constant keeps its proto number")
 
+	var msg = EverythingMessage.new()
+	msg.basic_enum = gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_THREE
+	var msg2 = EverythingMessage.new()
+	msg2.from_byte_array(msg.to_byte_array())
+	assert_eq(msg2.basic_enum, gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_THREE, "Enum field roundtrip")
+
+	for property in msg.get_property_list():
+		if property["name"] == "basic_enum":
+			assert_eq(property["hint"], PROPERTY_HINT_ENUM, "Enum field uses PROPERTY_HINT_ENUM")
+			assert_eq(property["hint_string"], "BASIC_TEST_ENUM_UNSPECIFIED:0,BASIC_TEST_ENUM_ONE:1,BASIC_TEST_ENUM_TWO:2,BASIC_TEST_ENUM_THREE:3", "Enum hint lists values")
+			assert_eq(property["class_name"], &"gdbufgenEnums.BasicTestEnum", "Enum field is typed")
+			assert_true(property["usage"] & PROPERTY_USAGE_CLASS_IS_ENUM, "Enum field is flagged as enum")
+
+	# Nested enums are registered on their owning message class
+	assert_eq(OuterNestedMessage.Scope.SCOPE_GLOBAL, 2, "Nested enum constant")
+	assert_eq(OuterNestedMessageInnerNestedMessage.Mood.MOOD_HAPPY, 5, "Enum nested two levels deep")
+	var outer = OuterNestedMessage.new()
+	outer.scope = OuterNestedMessage.Scope.SCOPE_LOCAL
+	outer.inner_mood = OuterNestedMessageInnerNestedMessage.Mood.MOOD_HAPPY
+	var outer2 = OuterNestedMessage.new()
+	outer2.from_byte_array(outer.to_byte_array())
+	assert_eq(outer2.scope, OuterNestedMessage.Scope.SCOPE_LOCAL, "Nested enum field roundtrip")
+	assert_eq(outer2.inner_mood, OuterNestedMessageInnerNestedMessage.Mood.MOOD_HAPPY, "Nested enum from another message roundtrip")
+
+
+func test_field_presence():
+	print("--- test_field_presence ---")
+	# proto3 optional scalar
+	var msg = SpecialFieldTypesMessage.new()
+	assert_eq(msg.has_optional_string(), false, "Optional field unset by default")
+	msg.optional_string = ""
+	assert_eq(msg.has_optional_string(), true, "Optional field set to default value is present")
+
+	var msg2 = SpecialFieldTypesMessage.new()
+	msg2.from_byte_array(msg.to_byte_array())
+	assert_eq(msg2.has_optional_string(), true, "Optional field presence survives roundtrip")
+
+	msg.clear_optional_string()
+	assert_eq(msg.has_optional_string(), false, "Optional field cleared")
+	assert_eq(msg.to_byte_array().size(), 0, "Cleared optional field is not encoded")
+
+	# Message field
+	var outer = OuterNestedMessage.new()
+	assert_eq(outer.has_inner_msg(), false, "Message field unset by default")
+	outer.inner_msg = OuterNestedMessageInnerNestedMessage.new()
+	assert_eq(outer.has_inner_msg(), true, "Message field set")
+	outer.clear_inner_msg()
+	assert_eq(outer.inner_msg, null, "Message field cleared")
+
+	# Wrapper type
+	var wkt = GoogleWellKnownTypesMessage.new()
+	assert_eq(wkt.has_int32_wrapper(), false, "Wrapper field unset by default")
+	wkt.int32_wrapper = 0
+	var wkt2 = GoogleWellKnownTypesMessage.new()
+	wkt2.from_byte_array(wkt.to_byte_array())
+	assert_eq(wkt2.has_int32_wrapper(), true, "Wrapper field set to zero is present after roundtrip")
+	assert_eq(wkt2.has_string_wrapper(), false, "Unset wrapper field is absent after roundtrip")
+
+	# Oneof member
+	var oneof = OneOfMessage.new()
+	oneof.int32_field = 5
+	assert_eq(oneof.has_int32_field(), true, "Oneof member present")
+	assert_eq(oneof.has_string_field(), false, "Other oneof member absent")
+	oneof.clear_int32_field()
+	assert_eq(oneof.get_test_oneof_case(), OneOfMessage.TEST_ONEOF_NOT_SET, "Clearing oneof member clears case")
+
+func test_proto2_defaults():
+	print("--- test_proto2_defaults ---")
+	var msg = LegacyDefaultsMessage.new()
+	assert_eq(msg.int32_field, -42, "Int default")
+	assert_eq(msg.double_field, 1.5, "Double default")
+	assert_eq(msg.float_field, INF, "Float default")
+	assert_eq(msg.bool_field, true, "Bool default")
+	assert_eq(msg.string_field, "h\"llo\n", "String default")
+	assert_eq(msg.bytes_field, PackedByteArray([1, 2, 97, 98, 99]), "Bytes default")
+	assert_eq(msg.color, 2, "Enum default")
+	assert_eq(msg.no_default, 0, "Field without declared default")
+	assert_eq(msg.has_int32_field(), false, "Default value is not presence")
+	assert_eq(msg.to_byte_array().size(), 0, "Defaults are not encoded")
+
+	msg.int32_field = 7
+	msg.clear_int32_field()
+	assert_eq(msg.int32_field, -42, "Clearing restores the declared default")
+
+	var msg2 = LegacyDefaultsMessage.new()
+	msg2.string_field = "changed"
+	msg2.from_byte_array(PackedByteArray())
+	assert_eq(msg2.string_field, "h\"llo\n", "Decoding an absent field restores the declared default")
+
+func test_required_fields():
+	print("--- test_required_fields ---")
+	var msg = LegacyRequiredMessage.new()
+	assert_eq(msg.is_initialized(), false, "Required fields unset")
+	assert_eq(msg.get_missing_required_fields(), PackedStringArray(["name", "id"]), "Missing required fields listed")
+	assert_eq(msg.to_byte_array().size(), 0, "Encoding refused while required fields are missing")
+
+	msg.name = "root"
+	msg.id = 1
+	assert_eq(msg.is_initialized(), true, "Required fields set")
+
+	var child = LegacyRequiredMessage.new()
+	child.name = "child"
+	msg.child = child
+	var item = LegacyRequiredMessage.new()
+	item.id = 3
+	msg.items = [item]
+	assert_eq(msg.get_missing_required_fields(), PackedStringArray(["child.id", "items[0].name"]), "Missing nested required fields listed")
+
+	child.id = 2
+	item.name = "item"
+	var bytes = msg.to_byte_array()
+	assert_true(bytes.size() > 0, "Encoding succeeds once initialized")
+	var decoded = LegacyRequiredMessage.new()
+	assert_eq(decoded.from_byte_array(bytes), OK, "Initialized message decodes")
+	assert_eq(decoded.child.id, 2, "Nested required field roundtrip")
+
+	var incomplete = LegacyDefaultsMessage.new()
+	incomplete.int32_field = 1
+	assert_eq(LegacyRequiredMessage.new().from_byte_array(incomplete.to_byte_array()), ERR_PARSE_ERROR, "Decoding refused when required fields are missing")
+
+func test_groups():
+	print("--- test_groups ---")
+	var msg = LegacyGroupMessage.new()
+	assert_eq(msg.has_settings(), false, "Optional group unset")
+	var settings = LegacyGroupMessageSettings.new()
+	assert_eq(settings.volume, 7, "Default inside a group")
+	msg.settings = settings
+	assert_eq(msg.has_settings(), true, "Optional group set")
+	assert_eq(msg.get_missing_required_fields(), PackedStringArray(["settings.label"]), "Required field inside a group")
+	assert_eq(msg.to_byte_array().size(), 0, "Encoding refused while a group misses required fields")
+
+	settings.label = "audio"
+	var first = LegacyGroupMessageEntry.new()
+	first.key = "a"
+	var second = LegacyGroupMessageEntry.new()
+	second.key = "b"
+	second.color = gdbufgenLegacyEnums.LegacyColor.LEGACY_COLOR_RED
+	msg.entry = [first, second]
+	msg.revision = 3
+	var bytes = msg.to_byte_array()
+	assert_eq(bytes[0], 0x0B, "Group starts with a START_GROUP tag")
+	assert_true(bytes.has(0x0C), "Group ends with an END_GROUP tag")
+
+	var decoded = LegacyGroupMessage.new()
+	assert_eq(decoded.from_byte_array(bytes), OK, "Groups decode")
+	assert_eq(decoded.settings.label, "audio", "Optional group roundtrip")
+	assert_eq(decoded.settings.volume, 7, "Default inside a decoded group")
+	assert_eq(decoded.settings.has_volume(), false, "Unset field inside a decoded group")
+	assert_eq(decoded.entry.size(), 2, "Repeated group roundtrip")
+	assert_eq(decoded.entry[0].color, gdbufgenLegacyEnums.LegacyColor.LEGACY_COLOR_BLUE, "Enum default inside a repeated group")
+	assert_eq(decoded.entry[1].color, gdbufgenLegacyEnums.LegacyColor.LEGACY_COLOR_RED, "Enum inside a repeated group")
+	assert_eq(decoded.revision, 3, "Field after the groups")
+
+	var incomplete = PackedByteArray([0x0B, 0x18, 0x01, 0x0C])
+	assert_eq(LegacyGroupMessage.new().from_byte_array(incomplete), ERR_PARSE_ERROR, "Decoding refused when a group misses a required field")
+
+func test_struct_value():
+	print("--- test_struct_value ---")
+	var msg = GoogleWellKnownTypesMessage.new()
+	msg.struct_field = {
+		"name": "config",
+		"count": 3,
+		"ratio": 0.5,
+		"enabled": true,
+		"missing": null,
+		"limits": [1, "two", false],
+		"nested": {"inner": {"deep": "value"}},
+	}
+	msg.value_field = "text"
+	msg.list_value_field = [null, 1.5, {"key": "value"}, [true]]
+	msg.struct_list = [{"a": 1}, {}]
+
+	var decoded = GoogleWellKnownTypesMessage.new()
+	assert_eq(decoded.from_byte_array(msg.to_byte_array()), OK, "Struct message decodes")
+	var s = decoded.struct_field
+	assert_eq(s["name"], "config", "Struct string")
+	assert_eq(s["count"], 3.0, "Struct number")
+	assert_eq(s["ratio"], 0.5, "Struct float")
+	assert_eq(s["enabled"], true, "Struct bool")
+	assert_true(s.has("missing") and s["missing"] == null, "Struct null")
+	assert_eq(s["limits"], [1.0, "two", false], "Struct list")
+	assert_eq(s["nested"]["inner"]["deep"], "value", "Struct nested struct")
+	assert_eq(decoded.value_field, "text", "Value string")
+	assert_eq(decoded.list_value_field, [null, 1.5, {"key": "value"}, [true]], "ListValue")
+	assert_eq(decoded.struct_list.size(), 2, "Repeated Struct size")
+	assert_eq(decoded.struct_list[0]["a"], 1.0, "Repeated Struct content")
+
+	msg.value_field = null
+	decoded.from_byte_array(msg.to_byte_array())
+	assert_eq(decoded.has_value_field(), true, "Null Value is present")
+	assert_eq(decoded.value_field, null, "Null Value roundtrip")
+
+func test_any():
+	print("--- test_any ---")
+	var basic = BasicTestMessage.new()
+	basic.int32_field = 42
+	basic.string_field = "packed"
+
+	var any = gdbufgenAny.pack_any(basic)
+	assert_eq(any["type_url"], "type.googleapis.com/BasicTestMessage", "Any type URL")
+	assert_eq(gdbufgenAny.get_type_url("LegacyRequiredMessage"), "type.googleapis.com/legacy.LegacyRequiredMessage", "Type URL includes the package")
+	assert_true(gdbufgenAny.any_is(any, "BasicTestMessage"), "any_is matches packed class")
+	assert_true(not gdbufgenAny.any_is(any, "OneOfMessage"), "any_is rejects other class")
+
+	var unpacked = gdbufgenAny.unpack_any(any)
+	assert_true(unpacked is BasicTestMessage, "Unpacked class")
+	assert_eq(unpacked.int32_field, 42, "Unpacked int")
+	assert_eq(unpacked.string_field, "packed", "Unpacked string")
+
+	var other_host = {"type_url": "example.com/types/BasicTestMessage", "value": any["value"]}
+	assert_true(gdbufgenAny.any_is(other_host, "BasicTestMessage"), "Only the last type URL segment matters")
+
+	# Any fields round-trip as embedded messages
+	var msg = GoogleWellKnownTypesMessage.new()
+	msg.any_field = any
+	var inner = OneOfMessage.new()
+	inner.string_field = "event"
+	msg.any_list = [any, gdbufgenAny.pack_any(inner)]
+	var decoded = GoogleWellKnownTypesMessage.new()
+	assert_eq(decoded.from_byte_array(msg.to_byte_array()), OK, "Any message decodes")
+	assert_eq(decoded.any_field["type_url"], any["type_url"], "Any field type URL roundtrip")
+	assert_eq(gdbufgenAny.unpack_any(decoded.any_field).int32_field, 42, "Any field payload roundtrip")
+	assert_eq(gdbufgenAny.unpack_any(decoded.any_list[1]).string_field, "event", "Repeated Any roundtrip")
+
+func test_field_mask():
+	print("--- test_field_mask ---")
+	var wkt = GoogleWellKnownTypesMessage.new()
+	wkt.update_mask = PackedStringArray(["outer_string", "inner_msg.inner_string"])
+	var wkt2 = GoogleWellKnownTypesMessage.new()
+	wkt2.from_byte_array(wkt.to_byte_array())
+	assert_eq(wkt2.update_mask, PackedStringArray(["outer_string", "inner_msg.inner_string"]), "FieldMask roundtrip")
+
+	var cached = OuterNestedMessage.new()
+	cached.outer_string = "old"
+	var cached_inner = OuterNestedMessageInnerNestedMessage.new()
+	cached_inner.inner_string = "old inner"
+	cached.inner_msg = cached_inner
+
+	var update = OuterNestedMessage.new()
+	update.outer_string = "ignored"
+	var update_inner = OuterNestedMessageInnerNestedMessage.new()
+	update_inner.inner_string = "new inner"
+	update.inner_msg = update_inner
+
+	assert_eq(cached.apply_field_mask(update, wkt2.update_mask.slice(1)), OK, "Apply nested path")
+	assert_eq(cached.outer_string, "old", "Unmasked field untouched")
+	assert_eq(cached.inner_msg.inner_string, "new inner", "Masked nested field applied")
+	assert_true(cached.inner_msg == cached_inner, "Nested path updates the existing sub-message")
+
+	assert_eq(cached.apply_field_mask(update, PackedStringArray(["outer_string", "inner_msg"])), OK, "Apply top-level paths")
+	assert_eq(cached.outer_string, "ignored", "Masked field applied")
+	assert_true(cached.inner_msg != update_inner, "Whole sub-message is copied")
+	assert_eq(cached.inner_msg.inner_string, "new inner", "Copied sub-message content")
+
+	assert_eq(cached.apply_field_mask(OuterNestedMessage.new(), PackedStringArray(["inner_msg"])), OK, "Apply unset sub-message")
+	assert_eq(cached.has_inner_msg(), false, "Unset sub-message in source clears the target")
+	assert_eq(cached.apply_field_mask(update, PackedStringArray(["bogus"])), ERR_INVALID_PARAMETER, "Unknown path rejected")
+
+	var partial = update.copy_with_mask(PackedStringArray(["inner_msg.inner_string"]))
+	assert_eq(partial.outer_string, "", "copy_with_mask leaves unmasked fields unset")
+	assert_eq(partial.inner_msg.inner_string, "new inner", "copy_with_mask copies masked fields")
+
+func test_unsigned_integers():
+	print("--- test_unsigned_integers ---")
+	var msg = BasicTestMessage.new()
+	msg.fixed32_field = 4000000000
+	msg.uint32_field = 4294967295
+	# Default wrap policy, values above INT64_MAX keep their bits
+	msg.uint64_field = -1
+	msg.fixed64_field = -2
+	msg.sfixed32_field = -5
+
+	var msg2 = BasicTestMessage.new()
+	msg2.from_byte_array(msg.to_byte_array())
+	assert_eq(msg2.fixed32_field, 4000000000, "fixed32 is unsigned")
+	assert_eq(msg2.uint32_field, 4294967295, "uint32 max")
+	assert_eq(msg2.uint64_field, -1, "uint64 max wraps to -1")
+	assert_eq(msg2.fixed64_field, -2, "fixed64 above INT64_MAX wraps")
+	assert_eq(msg2.sfixed32_field, -5, "sfixed32 stays signed")
+
+	var bytes = PackedByteArray()
+	bytes.resize(8)
+	bytes.encode_s64(0, msg2.uint64_field)
+	assert_eq(bytes.decode_u64(0), msg2.uint64_field, "Wrapped value keeps the uint64 bits")
+
+func test_wrappers():
+	print("--- test_wrappers ---")
+	var msg = GoogleWellKnownTypesMessage.new()
+	assert_eq(msg.double_wrapper, null, "Unset wrapper reads back as null")
+	assert_eq(msg.string_wrapper, null, "Unset StringValue reads back as null")
+
+	msg.double_wrapper = 2.5
+	msg.float_wrapper = 0.5
+	msg.int64_wrapper = -9000000000
+	msg.uint64_wrapper = 9000000000
+	msg.int32_wrapper = 0
+	msg.uint32_wrapper = 4000000000
+	msg.bool_wrapper = false
+	msg.string_wrapper = "wrapped"
+	msg.bytes_wrapper = PackedByteArray([1, 2])
+	msg.int32_wrapper_list = [1, 0, -1]
+
+	var decoded = GoogleWellKnownTypesMessage.new()
+	decoded.from_byte_array(msg.to_byte_array())
+	assert_eq(decoded.double_wrapper, 2.5, "DoubleValue")
+	assert_eq(decoded.float_wrapper, 0.5, "FloatValue")
+	assert_eq(decoded.int64_wrapper, -9000000000, "Int64Value")
+	assert_eq(decoded.uint64_wrapper, 9000000000, "UInt64Value")
+	assert_eq(decoded.int32_wrapper, 0, "Int32Value holding zero is not null")
+	assert_eq(decoded.uint32_wrapper, 4000000000, "UInt32Value")
+	assert_eq(decoded.bool_wrapper, false, "BoolValue holding false is not null")
+	assert_eq(decoded.string_wrapper, "wrapped", "StringValue")
+	assert_eq(decoded.bytes_wrapper, PackedByteArray([1, 2]), "BytesValue")
+	assert_eq(decoded.int32_wrapper_list, [1, 0, -1], "Repeated Int32Value")
+
+	decoded.double_wrapper = null
+	assert_eq(decoded.has_double_wrapper(), false, "Assigning null unsets the wrapper")
+	var decoded2 = GoogleWellKnownTypesMessage.new()
+	decoded2.from_byte_array(decoded.to_byte_array())
+	assert_eq(decoded2.double_wrapper, null, "Unset wrapper stays null after roundtrip")
+
+func find_property(obj, name):
+	for property in obj.get_property_list():
+		if property["name"] == name:
+			return property
+	return {}
+
+func test_typed_arrays():
+	print("--- test_typed_arrays ---")
+	var msg = RepeatedComplexMessage.new()
+	assert_eq(msg.messages.get_typed_builtin(), TYPE_OBJECT, "Repeated message array is typed")
+	assert_eq(msg.messages.get_typed_class_name(), &"BasicTestMessage", "Repeated message array class")
+	assert_eq(msg.enums.get_typed_builtin(), TYPE_INT, "Repeated enum array is typed")
+
+	var messages_property = find_property(msg, "messages")
+	assert_eq(messages_property["hint"], PROPERTY_HINT_ARRAY_TYPE, "Repeated message hint")
+	assert_eq(messages_property["hint_string"], "BasicTestMessage", "Repeated message hint string")
+	var enums_property = find_property(msg, "enums")
+	assert_true(enums_property["hint_string"].begins_with("2/2:BASIC_TEST_ENUM_UNSPECIFIED:0"), "Repeated enum hint string")
+
+	# Untyped arrays are converted on assignment
+	msg.enums = [1, 2]
+	assert_eq(msg.enums.get_typed_builtin(), TYPE_INT, "Assigned array stays typed")
+	assert_eq(msg.enums, [1, 2], "Assigned array content")
+
+	var special = SpecialFieldTypesMessage.new()
+	special.repeated_packed_double = [1.5, 2]
+	assert_eq(special.repeated_packed_double.get_typed_builtin(), TYPE_FLOAT, "Repeated double array is typed")
+	assert_eq(special.repeated_packed_double[1], 2.0, "Ints are converted to floats")
+
+	var decoded = SpecialFieldTypesMessage.new()
+	decoded.from_byte_array(special.to_byte_array())
+	assert_eq(decoded.repeated_packed_double.get_typed_builtin(), TYPE_FLOAT, "Decoded array is typed")
+	assert_eq(decoded.repeated_packed_double, [1.5, 2.0], "Decoded array content")
+
+	# Wrapper elements are nullable Variants, those arrays stay untyped
+	var wkt = GoogleWellKnownTypesMessage.new()
+	assert_eq(wkt.int32_wrapper_list.is_typed(), false, "Repeated wrapper array is untyped")
+
+func test_typed_dictionaries():
+	print("--- test_typed_dictionaries ---")
+	var msg = MapMessage.new()
+	assert_eq(msg.string_int_map.get_typed_key_builtin(), TYPE_STRING, "Map key is typed")
+	assert_eq(msg.string_int_map.get_typed_value_builtin(), TYPE_INT, "Map value is typed")
+	assert_eq(msg.int_msg_map.get_typed_key_builtin(), TYPE_INT, "Message map key is typed")
+	assert_eq(msg.int_msg_map.get_typed_value_class_name(), &"BasicTestMessage", "Message map value class")
+
+	var property = find_property(msg, "int_msg_map")
+	assert_eq(property["hint"], PROPERTY_HINT_DICTIONARY_TYPE, "Map hint")
+	assert_eq(property["hint_string"], "int;BasicTestMessage", "Map hint string")
+
+	# Untyped dictionaries are converted on assignment
+	msg.string_string_map = {"greeting": "hello"}
+	assert_eq(msg.string_string_map.get_typed_value_builtin(), TYPE_STRING, "Assigned dictionary stays typed")
+
+	var decoded = MapMessage.new()
+	decoded.from_byte_array(msg.to_byte_array())
+	assert_eq(decoded.string_string_map.get_typed_value_builtin(), TYPE_STRING, "Decoded dictionary is typed")
+	assert_eq(decoded.string_string_map["greeting"], "hello", "Decoded string map value")
+
+func test_map_values():
+	print("--- test_map_values ---")
+	var msg = MapMessage.new()
+	var item = BasicTestMessage.new()
+	item.int32_field = 7
+	msg.int_msg_map = {3: item}
+	msg.enum_map = {"two": 2}
+	msg.bytes_map = {"blob": PackedByteArray([0, 255]), "empty": PackedByteArray()}
+	var dependency = DependencyMessage.new()
+	dependency.name = "dep"
+	msg.dependency_map = {"d": dependency}
+	msg.timestamp_map = {-1: 1700000000123}
+	msg.duration_map = {true: 1.5}
+	msg.wrapper_map = {"zero": 0, "unset": null}
+	msg.struct_map = {"s": {"enabled": true}}
+
+	var decoded = MapMessage.new()
+	assert_eq(decoded.from_byte_array(msg.to_byte_array()), OK, "Map values decode")
+	assert_eq(decoded.int_msg_map[3].int32_field, 7, "Message map value")
+	assert_eq(decoded.int_msg_map.get_typed_value_class_name(), &"BasicTestMessage", "Decoded message map is typed")
+	assert_eq(decoded.enum_map["two"], 2, "Enum map value")
+	assert_eq(decoded.bytes_map["blob"], PackedByteArray([0, 255]), "Bytes map value")
+	assert_eq(decoded.bytes_map["empty"], PackedByteArray(), "Empty bytes map value")
+	assert_eq(decoded.dependency_map["d"].name, "dep", "Message from another package as map value")
+	assert_eq(decoded.timestamp_map[-1], 1700000000123, "Timestamp map value with int64 key")
+	assert_eq(decoded.duration_map[true], 1.5, "Duration map value with bool key")
+	assert_eq(decoded.wrapper_map["zero"], 0, "Wrapper map value")
+	assert_eq(decoded.wrapper_map["unset"], null, "Null wrapper map value")
+	assert_eq(decoded.struct_map["s"]["enabled"], true, "Struct map value")
+
+func test_deep_nesting():
+	print("--- test_deep_nesting ---")
+	var root = RecursiveMessage.new()
+	root.name = "root"
+	var current = root
+	for i in range(32):
+		var child = RecursiveMessage.new()
+		child.name = "child " + str(i)
+		var children: Array[RecursiveMessage] = [child]
+		current.children = children
+		current = child
+	var parent = RecursiveMessage.new()
+	parent.name = "parent"
+	current.parent = parent
+
+	var decoded = RecursiveMessage.new()
+	assert_eq(decoded.from_byte_array(root.to_byte_array()), OK, "Deep tree decodes")
+	var node = decoded
+	var depth = 0
+	while not node.children.is_empty():
+		node = node.children[0]
+		depth += 1
+	assert_eq(depth, 32, "Every level survives the round trip")
+	assert_eq(node.name, "child 31", "Deepest child")
+	assert_eq(node.parent.name, "parent", "Message field on the deepest child")
+	assert_eq(decoded.to_byte_array(), root.to_byte_array(), "Re-encoding is stable")
+
+func test_streaming():
+	print("--- test_streaming ---")
+	var first = BasicTestMessage.new()
+	first.int32_field = 1
+	first.string_field = "first"
+	var second = BasicTestMessage.new()
+	var payload = PackedByteArray()
+	payload.resize(200000)
+	payload.fill(7)
+	second.bytes_field = payload
+
+	var peer = StreamPeerBuffer.new()
+	assert_eq(first.write_to(peer), OK, "Write to StreamPeer")
+	assert_eq(second.write_to(peer), OK, "Write a second message")
+	peer.seek(0)
+	var decoded = BasicTestMessage.new()
+	assert_eq(decoded.read_from(peer), OK, "Read from StreamPeer")
+	assert_eq(decoded.string_field, "first", "First message")
+	assert_eq(decoded.read_from(peer), OK, "Read the second message")
+	assert_eq(decoded.bytes_field, payload, "Message larger than one chunk")
+	assert_eq(decoded.read_from(peer), ERR_FILE_EOF, "End of stream")
+
+	var raw = StreamPeerBuffer.new()
+	assert_eq(first.write_to(raw, false), OK, "Write without length prefix")
+	assert_eq(raw.data_array, first.to_byte_array(), "Same bytes as to_byte_array")
+	raw.seek(0)
+	var undelimited = BasicTestMessage.new()
+	assert_eq(undelimited.read_from(raw, false), OK, "Read up to the end of the stream")
+	assert_true(undelimited.equals(first), "Message without length prefix")
+	assert_eq(undelimited.read_from(raw, false), OK, "Empty stream")
+	assert_eq(undelimited.string_field, "", "Empty stream is the empty message")
+	raw.data_array = first.to_byte_array().slice(0, -3)
+	assert_eq(undelimited.read_from(raw, false), ERR_PARSE_ERROR, "Stream ending inside a field")
+
+	var maps = MapMessage.new()
+	var item = BasicTestMessage.new()
+	item.int32_field = 7
+	maps.int_msg_map = {3: item}
+	maps.wrapper_map = {"zero": 0, "unset": null}
+	maps.struct_map = {"s": {"enabled": true}}
+	var choice = OneOfMessage.new()
+	choice.message_field = item
+	peer.clear()
+	assert_eq(maps.write_to(peer), OK, "Write map fields")
+	assert_eq(choice.write_to(peer), OK, "Write a oneof message")
+	peer.seek(0)
+	var decoded_maps = MapMessage.new()
+	assert_eq(decoded_maps.read_from(peer), OK, "Read map fields")
+	assert_true(decoded_maps.equals(maps), "Map fields from the stream")
+	assert_eq(decoded_maps.wrapper_map["unset"], null, "Null wrapper map value from the stream")
+	var decoded_choice = OneOfMessage.new()
+	assert_eq(decoded_choice.read_from(peer), OK, "Read a oneof message")
+	assert_eq(decoded_choice.get_test_oneof_case(), OneOfMessage.kMessageField, "Oneof case from the stream")
+	assert_eq(decoded_choice.message_field.int32_field, 7, "Oneof message from the stream")
+
+	var groups = LegacyGroupMessage.new()
+	var settings = LegacyGroupMessageSettings.new()
+	settings.label = "audio"
+	groups.settings = settings
+	var entry = LegacyGroupMessageEntry.new()
+	entry.key = "a"
+	groups.entry = [entry, LegacyGroupMessageEntry.new()]
+	groups.revision = 3
+	raw.clear()
+	assert_eq(groups.write_to(raw, false), OK, "Write groups")
+	assert_eq(raw.data_array, groups.to_byte_array(), "Groups as START_GROUP and END_GROUP like to_byte_array")
+	raw.seek(0)
+	var decoded_groups = LegacyGroupMessage.new()
+	assert_eq(decoded_groups.read_from(raw, false), OK, "Read groups")
+	assert_true(decoded_groups.equals(groups), "Groups from the stream")
+	peer.clear()
+	assert_eq(groups.write_to(peer), OK, "Write groups with a length prefix")
+	peer.seek(0)
+	assert_eq(decoded_groups.read_from(peer), OK, "Read delimited groups")
+	assert_eq(decoded_groups.entry[0].key, "a", "Repeated group from the stream")
+	raw.data_array = PackedByteArray([0x0B, 0x18, 0x01, 0x0C])
+	assert_eq(decoded_groups.read_from(raw, false), ERR_PARSE_ERROR, "Group missing a required field from the stream")
+	raw.data_array = PackedByteArray([0x0B, 0x12, 0x01, 0x61])
+	assert_eq(decoded_groups.read_from(raw, false), ERR_PARSE_ERROR, "Group without END_GROUP")
+
+	var path = "user://test_streaming.bin"
+	var file = FileAccess.open(path, FileAccess.WRITE)
+	var tree = RecursiveMessage.new()
+	tree.name = "root"
+	var child = RecursiveMessage.new()
+	child.name = "child"
+	var children: Array[RecursiveMessage] = [child]
+	tree.children = children
+	assert_eq(tree.write_to(file), OK, "Write to FileAccess")
+	file.close()
+	file = FileAccess.open(path, FileAccess.READ)
+	var decoded_tree = RecursiveMessage.new()
+	assert_eq(decoded_tree.read_from(file), OK, "Read from FileAccess")
+	assert_eq(decoded_tree.children[0].name, "child", "Nested message from FileAccess")
+	file.close()
+	DirAccess.remove_absolute(path)
+
+	assert_eq(first.write_to(RefCounted.new()), ERR_INVALID_PARAMETER, "Other objects are rejected")
+
+func test_json():
+	print("--- test_json ---")
+	var msg = BasicTestMessage.new()
+	msg.int32_field = 7
+	msg.int64_field = 9007199254740993
+	msg.string_field = "hi"
+	msg.bytes_field = PackedByteArray([1, 2, 3])
+	var json = JSON.parse_string(msg.to_json())
+	assert_eq(json["int32Field"], 7.0, "Keys are lowerCamelCase")
+	assert_eq(json["int64Field"], "9007199254740993", "64-bit integers are strings")
+	assert_eq(json["bytesField"], "AQID", "Bytes are base64")
+	assert_eq(json.has("doubleField"), false, "Defaults are omitted")
+
+	var decoded = BasicTestMessage.new()
+	assert_eq(decoded.from_json(msg.to_json()), OK, "from_json accepts to_json output")
+	assert_eq(decoded.int64_field, 9007199254740993, "int64 roundtrip")
+	assert_eq(decoded.bytes_field, msg.bytes_field, "bytes roundtrip")
+
+	var everything = EverythingMessage.new()
+	everything.basic_enum = gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_THREE
+	assert_eq(JSON.parse_string(everything.to_json())["basicEnum"], "BASIC_TEST_ENUM_THREE", "Enums are names")
+	var everything2 = EverythingMessage.new()
+	assert_eq(everything2.from_json('{"basicEnum": "BASIC_TEST_ENUM_TWO"}'), OK, "Enum name is accepted")
+	assert_eq(everything2.basic_enum, gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_TWO, "Enum from name")
+	assert_eq(everything2.from_json('{"basicEnum": 3}'), OK, "Enum number is accepted")
+	assert_eq(everything2.basic_enum, gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_THREE, "Enum from number")
+
+	var options = {"preserve_proto_field_names": true, "emit_defaults": true}
+	json = JSON.parse_string(msg.to_json(options))
+	assert_eq(json["int32_field"], 7.0, "Proto names are preserved")
+	assert_eq(json["double_field"], 0.0, "Defaults are emitted")
+
+	assert_eq(decoded.from_json('{"int32_field": 3, "int64Field": 4}'), OK, "Both name forms are accepted")
+	assert_eq(decoded.int32_field, 3, "Proto name")
+	assert_eq(decoded.int64_field, 4, "JSON name")
+	assert_eq(decoded.string_field, "", "from_json resets other fields")
+	assert_true(decoded.from_json('{"unknownField": 1}') != OK, "Unknown fields are rejected")
+	assert_eq(decoded.from_json('{"unknownField": 1}', {"ignore_unknown_fields": true}), OK, "Unknown fields can be ignored")
+	assert_true(decoded.from_json('{"int32Field": "x"}') != OK, "Mistyped values are rejected")
+
+	var oneof = OneOfMessage.new()
+	assert_eq(oneof.from_json('{"int32Field": 4}'), OK, "A single oneof member is accepted")
+	assert_eq(oneof.get_test_oneof_case(), OneOfMessage.kInt32Field, "Oneof case from JSON")
+	assert_eq(oneof.from_json('{"stringField": "a", "int32Field": 4}'), ERR_PARSE_ERROR, "Several members of a oneof are rejected")
+	assert_eq(oneof.from_json('{"stringField": null, "int32Field": 4}'), OK, "A null oneof member does not count")
+
+	var wkt = GoogleWellKnownTypesMessage.new()
+	wkt.timestamp_field = 1500
+	wkt.duration_field = 1.5
+	wkt.int32_wrapper = 0
+	wkt.update_mask = PackedStringArray(["outer_string", "inner_msg.inner_string"])
+	json = JSON.parse_string(wkt.to_json())
+	assert_eq(json["timestampField"], "1970-01-01T00:00:01.500Z", "Timestamp is RFC 3339")
+	assert_eq(json["durationField"], "1.500s", "Duration is seconds")
+	assert_eq(json["int32Wrapper"], 0.0, "Set wrapper is emitted")
+	assert_eq(json["updateMask"], "outerString,innerMsg.innerString", "FieldMask is a camelCase string")
+	var wkt2 = GoogleWellKnownTypesMessage.new()
+	assert_eq(wkt2.from_json(wkt.to_json()), OK, "Well-known types roundtrip")
+	assert_eq(wkt2.timestamp_field, 1500, "Timestamp roundtrip")
+	assert_eq(wkt2.duration_field, 1.5, "Duration roundtrip")
+	assert_eq(wkt2.has_int32_wrapper(), true, "Wrapper roundtrip")
+	assert_eq(wkt2.update_mask, wkt.update_mask, "FieldMask roundtrip")
+
+	var any_msg = GoogleWellKnownTypesMessage.new()
+	any_msg.any_field = gdbufgenAny.pack_any(msg)
+	json = JSON.parse_string(any_msg.to_json())
+	assert_eq(json["anyField"]["@type"], "type.googleapis.com/BasicTestMessage", "Any carries its type URL")
+	assert_eq(json["anyField"]["stringField"], "hi", "Any payload is inlined")
+	var any_decoded = GoogleWellKnownTypesMessage.new()
+	assert_eq(any_decoded.from_json(any_msg.to_json()), OK, "Any roundtrip")
+	assert_eq(gdbufgenAny.unpack_any(any_decoded.any_field).string_field, "hi", "Any payload roundtrip")
+
+func test_dictionary():
+	print("--- test_dictionary ---")
+	var msg = MapMessage.new()
+	var item = BasicTestMessage.new()
+	item.int32_field = 7
+	item.string_field = "item"
+	msg.int_msg_map = {3: item}
+	msg.string_int_map = {"a": 1}
+	var dict = msg.to_dictionary()
+	assert_eq(dict["int_msg_map"][3]["string_field"], "item", "Map message values become dictionaries")
+	assert_eq(dict["string_int_map"], {"a": 1}, "Map of scalars")
+	dict["string_int_map"]["a"] = 2
+	assert_eq(msg.string_int_map["a"], 1, "to_dictionary copies containers")
+
+	var decoded = MapMessage.new()
+	assert_eq(decoded.from_dictionary(dict), OK, "from_dictionary accepts to_dictionary output")
+	assert_eq(decoded.int_msg_map[3].string_field, "item", "Nested message from dictionary")
+	assert_eq(decoded.string_int_map["a"], 2, "Map of scalars from dictionary")
+
+	var tree = RecursiveMessage.new()
+	tree.name = "root"
+	var child = RecursiveMessage.new()
+	child.name = "child"
+	var children: Array[RecursiveMessage] = [child]
+	tree.children = children
+	var tree2 = RecursiveMessage.new()
+	assert_eq(tree2.from_dictionary(tree.to_dictionary()), OK, "Repeated messages roundtrip")
+	assert_eq(tree2.children[0].name, "child", "Repeated message element")
+
+	var oneof = OneOfMessage.new()
+	oneof.message_field = item
+	dict = oneof.to_dictionary()
+	assert_eq(dict.keys(), ["message_field"], "Only the set oneof member is written")
+	var oneof2 = OneOfMessage.new()
+	oneof2.from_dictionary(dict)
+	assert_eq(oneof2.get_test_oneof_case(), OneOfMessage.kMessageField, "Oneof case from dictionary")
+	assert_eq(oneof2.message_field.int32_field, 7, "Oneof message from dictionary")
+
+	var basic = BasicTestMessage.new()
+	var json_dict = JSON.parse_string(JSON.stringify(item.to_dictionary()))
+	assert_eq(basic.from_dictionary(json_dict), OK, "JSON numbers are converted")
+	assert_eq(basic.int32_field, 7, "Float converted to int")
+	assert_eq(basic.from_dictionary({"int32_field": "x", "unknown": 1}), OK, "Loose mode skips what it cannot convert")
+	assert_eq(basic.int32_field, 0, "Mistyped field keeps its default")
+	assert_eq(basic.from_dictionary({"unknown": 1}, true), ERR_INVALID_DATA, "Strict mode rejects unknown keys")
+	assert_eq(basic.from_dictionary({"int32_field": "x"}, true), ERR_INVALID_DATA, "Strict mode rejects type mismatches")
+	assert_eq(basic.from_dictionary({"double_field": 1}, true), OK, "Strict mode accepts int for float")
+	assert_eq(oneof2.from_dictionary({"message_field": {"int32_field": "x"}}, true), ERR_INVALID_DATA, "Strict mode checks nested messages")
+
+func test_text_format():
+	print("--- test_text_format ---")
+	var msg = OuterNestedMessage.new()
+	msg.outer_string = "say \"hi\"\n"
+	var inner = OuterNestedMessageInnerNestedMessage.new()
+	inner.inner_string = "In"
+	msg.inner_msg = inner
+	msg.scope = OuterNestedMessage.Scope.SCOPE_GLOBAL
+	var text = msg.to_text_format()
+	assert_eq(text, "outer_string: \"say \\\"hi\\\"\\n\"\ninner_msg {\n  inner_string: \"In\"\n}\nscope: SCOPE_GLOBAL\n", "Text format output")
+	var decoded = OuterNestedMessage.new()
+	assert_eq(decoded.from_text_format(text), OK, "from_text_format accepts to_text_format output")
+	assert_eq(decoded.outer_string, msg.outer_string, "Escaped string roundtrip")
+	assert_eq(decoded.inner_msg.inner_string, "In", "Nested block roundtrip")
+	assert_eq(decoded.scope, OuterNestedMessage.Scope.SCOPE_GLOBAL, "Enum roundtrip")
+
+	var basic = BasicTestMessage.new()
+	basic.bytes_field = PackedByteArray([0, 65, 255])
+	basic.uint64_field = -1
+	basic.float_field = 0.1
+	var basic2 = BasicTestMessage.new()
+	assert_eq(basic2.from_text_format(basic.to_text_format()), OK, "Scalars roundtrip")
+	assert_eq(basic2.bytes_field, basic.bytes_field, "Escaped bytes roundtrip")
+	assert_eq(basic2.uint64_field, -1, "uint64 above the int64 range")
+	assert_true(basic.to_text_format().contains("uint64_field: 18446744073709551615"), "uint64 printed unsigned")
+	assert_eq(basic2.float_field, basic.float_field, "Float roundtrip")
+
+	var handwritten = """
+		# Hand-edited level data
+		name: 'root' ;
+		children { name: "a" }
+		children < name: "b" >
+		children: [{ name: "c" }, { name: "d" }]
+	"""
+	var tree = RecursiveMessage.new()
+	assert_eq(tree.from_text_format(handwritten), OK, "Comments, separators and list syntax")
+	assert_eq(tree.children.size(), 4, "Repeated entries and lists append")
+	assert_eq(tree.children[3].name, "d", "List element")
+
+	var maps = MapMessage.new()
+	assert_eq(maps.from_text_format('string_int_map { key: "a" value: 0x10 } int_msg_map { key: 3 value { int32_field: 7 } } enum_map [{ key: "e" value: BASIC_TEST_ENUM_TWO }]'), OK, "Map entries")
+	assert_eq(maps.string_int_map["a"], 16, "Hexadecimal value")
+	assert_eq(maps.int_msg_map[3].int32_field, 7, "Message map value")
+	assert_eq(maps.enum_map["e"], gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_TWO, "Enum map value")
+	var maps2 = MapMessage.new()
+	assert_eq(maps2.from_text_format(maps.to_text_format()), OK, "Maps roundtrip")
+	assert_eq(maps2.int_msg_map[3].int32_field, 7, "Message map value roundtrip")
+
+	var wkt = GoogleWellKnownTypesMessage.new()
+	wkt.timestamp_field = 1500
+	wkt.int32_wrapper = 0
+	wkt.struct_field = {"name": "config", "list": [1.0, true, null]}
+	wkt.any_field = gdbufgenAny.pack_any(basic)
+	text = wkt.to_text_format()
+	assert_true(text.contains("timestamp_field {\n  seconds: 1\n  nanos: 500000000\n}"), "Timestamp is a message")
+	assert_true(text.contains("[type.googleapis.com/BasicTestMessage] {"), "Any uses the expanded form")
+	var wkt2 = GoogleWellKnownTypesMessage.new()
+	assert_eq(wkt2.from_text_format(text), OK, "Well-known types roundtrip")
+	assert_eq(wkt2.timestamp_field, 1500, "Timestamp roundtrip")
+	assert_eq(wkt2.has_int32_wrapper(), true, "Wrapper roundtrip")
+	assert_eq(wkt2.struct_field, wkt.struct_field, "Struct roundtrip")
+	assert_eq(gdbufgenAny.unpack_any(wkt2.any_field).bytes_field, basic.bytes_field, "Any roundtrip")
+
+	var groups = LegacyGroupMessage.new()
+	var settings = LegacyGroupMessageSettings.new()
+	settings.label = "audio"
+	groups.settings = settings
+	var entry = LegacyGroupMessageEntry.new()
+	entry.key = "a"
+	entry.color = gdbufgenLegacyEnums.LegacyColor.LEGACY_COLOR_RED
+	groups.entry = [entry]
+	text = groups.to_text_format()
+	assert_eq(text, "Settings {\n  label: \"audio\"\n}\nEntry {\n  key: \"a\"\n  color: LEGACY_COLOR_RED\n}\n", "Groups use their message type name")
+	var groups2 = LegacyGroupMessage.new()
+	assert_eq(groups2.from_text_format(text), OK, "Groups roundtrip")
+	assert_true(groups2.equals(groups), "Groups from text")
+	assert_eq(groups2.from_text_format('settings { label: "audio" }'), ERR_PARSE_ERROR, "Group under its field name")
+
+	assert_eq(decoded.from_text_format("unknown_field: 1"), ERR_PARSE_ERROR, "Unknown fields are rejected")
+	assert_eq(decoded.from_text_format("inner_msg { inner_string: \"x\""), ERR_PARSE_ERROR, "Unterminated block")
+	assert_eq(decoded.from_text_format("scope: SCOPE_NOWHERE"), ERR_PARSE_ERROR, "Unknown enum name")
+	assert_eq(decoded.from_text_format("outer_string: \"a\" outer_string: \"b\""), ERR_PARSE_ERROR, "Scalar field given twice")
+	assert_eq(decoded.from_text_format("inner_msg { } inner_msg { inner_string: \"x\" }"), ERR_PARSE_ERROR, "Message field given twice")
+	assert_eq(maps.from_text_format('string_int_map { key: "a" key: "b" value: 1 }'), ERR_PARSE_ERROR, "Map entry key given twice")
+	assert_eq(wkt.from_text_format("timestamp_field { seconds: 1 seconds: 2 }"), ERR_PARSE_ERROR, "Timestamp field given twice")
+	var oneof = OneOfMessage.new()
+	assert_eq(oneof.from_text_format('string_field: "a" int32_field: 1'), ERR_PARSE_ERROR, "Two members of one oneof")
+	assert_eq(oneof.from_text_format("int32_field: 1 int32_field: 2"), ERR_PARSE_ERROR, "Oneof member given twice")
+	assert_eq(oneof.from_text_format("int32_field: 1"), OK, "Single oneof member")
+	assert_eq(oneof.get_test_oneof_case(), OneOfMessage.kInt32Field, "Oneof case from text")
+
+func test_clone_equals_hash():
+	print("--- test_clone_equals_hash ---")
+	var tree = RecursiveMessage.new()
+	tree.name = "root"
+	var child = RecursiveMessage.new()
+	child.name = "child"
+	var children: Array[RecursiveMessage] = [child]
+	tree.children = children
+	var copy = tree.clone()
+	assert_true(copy.equals(tree), "Clone equals the original")
+	assert_eq(copy.hash(), tree.hash(), "Clone hashes like the original")
+	copy.children[0].name = "changed"
+	assert_eq(tree.children[0].name, "child", "Clone does not share nested messages")
+	assert_true(not copy.equals(tree), "Nested difference breaks equality")
+	assert_true(not tree.equals(null), "Nothing equals null")
+
+	var maps = MapMessage.new()
+	maps.string_int_map = {"a": 1, "b": 2}
+	var item = BasicTestMessage.new()
+	item.int32_field = 7
+	maps.int_msg_map = {3: item}
+	var reordered = MapMessage.new()
+	reordered.string_int_map = {"b": 2, "a": 1}
+	reordered.int_msg_map = {3: item.clone()}
+	assert_true(maps.equals(reordered), "Map order does not matter")
+	assert_eq(maps.hash(), reordered.hash(), "Map order does not change the hash")
+	reordered.int_msg_map[3].int32_field = 8
+	assert_true(not maps.equals(reordered), "Map message values compare by value")
+
+	var nan = BasicTestMessage.new()
+	nan.double_field = NAN
+	var nan2 = nan.clone()
+	assert_true(nan.equals(nan2), "NaN equals NaN")
+	assert_eq(nan.hash(), nan2.hash(), "NaN hashes consistently")
+	var zero = BasicTestMessage.new()
+	var negative_zero = BasicTestMessage.new()
+	negative_zero.double_field = -0.0
+	assert_true(zero.equals(negative_zero), "-0.0 equals 0.0")
+	assert_eq(zero.hash(), negative_zero.hash(), "-0.0 hashes like 0.0")
+
+	var oneof = OneOfMessage.new()
+	oneof.string_field = "stale"
+	oneof.int32_field = 42
+	var oneof2 = OneOfMessage.new()
+	oneof2.int32_field = 42
+	assert_true(oneof.equals(oneof2), "Inactive oneof members are ignored")
+	assert_eq(oneof.hash(), oneof2.hash(), "Inactive oneof members are not hashed")
+	oneof2.string_field = ""
+	assert_true(not oneof.equals(oneof2), "Oneof cases must match")
+
+	var special = SpecialFieldTypesMessage.new()
+	var special2 = SpecialFieldTypesMessage.new()
+	special2.optional_string = ""
+	assert_true(not special.equals(special2), "Presence is compared")
+
+	var wkt = GoogleWellKnownTypesMessage.new()
+	wkt.struct_field = {"nested": {"list": [1.0, NAN]}}
+	var wkt_copy = wkt.clone()
+	assert_true(wkt.equals(wkt_copy), "Struct compares by value")
+	wkt_copy.struct_field["nested"]["list"].append(2.0)
+	assert_eq(wkt.struct_field["nested"]["list"].size(), 2, "Clone deep copies Struct")
+
+func test_merge():
+	print("--- test_merge ---")
+	var state = EverythingMessage.new()
+	var basic = BasicTestMessage.new()
+	basic.int32_field = 1
+	basic.string_field = "kept"
+	state.basic_message = basic
+	var maps = MapMessage.new()
+	maps.string_int_map = {"a": 1, "b": 2}
+	state.map_message = maps
+	var repeated = RepeatedComplexMessage.new()
+	repeated.enums = [gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_ONE]
+	state.repeated_complex = repeated
+
+	var patch = EverythingMessage.new()
+	var basic_patch = BasicTestMessage.new()
+	basic_patch.int32_field = 2
+	patch.basic_message = basic_patch
+	var maps_patch = MapMessage.new()
+	maps_patch.string_int_map = {"b": 20, "c": 30}
+	patch.map_message = maps_patch
+	var repeated_patch = RepeatedComplexMessage.new()
+	repeated_patch.enums = [gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_TWO]
+	patch.repeated_complex = repeated_patch
+
+	assert_eq(state.merge_from_byte_array(patch.to_byte_array()), OK, "merge_from_byte_array")
+	assert_eq(state.basic_message.int32_field, 2, "Scalars overwrite")
+	assert_eq(state.basic_message.string_field, "kept", "Default values do not overwrite")
+	assert_eq(state.map_message.string_int_map, {"a": 1, "b": 20, "c": 30}, "Map entries overwrite by key")
+	assert_eq(state.repeated_complex.enums.size(), 2, "Repeated fields append")
+	assert_eq(state.repeated_complex.enums[1], gdbufgenEnums.BasicTestEnum.BASIC_TEST_ENUM_TWO, "Appended element")
+
+	var zeros = BasicTestMessage.new()
+	zeros.int32_field = 5
+	zeros.string_field = "kept"
+	# int32_field: 0 and string_field: "" written explicitly, to_byte_array() leaves them out
+	assert_eq(zeros.merge_from_byte_array(PackedByteArray([0x18, 0x00, 0x72, 0x00])), OK, "Merge explicit zero values")
+	assert_eq(zeros.int32_field, 0, "Explicit zero overwrites")
+	assert_eq(zeros.string_field, "", "Explicit empty string overwrites")
+
+	var required = LegacyRequiredMessage.new()
+	required.name = "root"
+	required.id = 1
+	var id_patch = PackedByteArray([0x10, 0x05])
+	assert_eq(required.merge_from_byte_array(id_patch), OK, "Patch without a required field the message already has")
+	assert_eq(required.name, "root", "Required field kept")
+	assert_eq(required.id, 5, "Required field patched")
+	assert_eq(LegacyRequiredMessage.new().merge_from_byte_array(id_patch), ERR_PARSE_ERROR, "Merge result still missing a required field")
+
+	var tree = RecursiveMessage.new()
+	tree.name = "root"
+	var child = RecursiveMessage.new()
+	child.name = "child"
+	var children: Array[RecursiveMessage] = [child]
+	tree.children = children
+	var merged = RecursiveMessage.new()
+	assert_eq(merged.merge_from(tree), OK, "merge_from")
+	assert_true(merged.equals(tree), "Merging into an empty message copies")
+	merged.children[0].name = "changed"
+	assert_eq(tree.children[0].name, "child", "Merged messages are copied")
+	assert_eq(tree.merge_from(tree), OK, "Merging a message into itself")
+	assert_eq(tree.children.size(), 2, "Self merge appends once")
+
+	var oneof = OneOfMessage.new()
+	oneof.string_field = "text"
+	var oneof_patch = OneOfMessage.new()
+	oneof_patch.int32_field = 0
+	oneof.merge_from(oneof_patch)
+	assert_eq(oneof.get_test_oneof_case(), OneOfMessage.kInt32Field, "Set oneof members replace the case, even with a default value")
+
+	var wkt = GoogleWellKnownTypesMessage.new()
+	wkt.struct_field = {"a": 1.0, "b": 2.0}
+	wkt.int32_wrapper = 5
+	var wkt_patch = GoogleWellKnownTypesMessage.new()
+	wkt_patch.struct_field = {"b": 3.0}
+	wkt_patch.int32_wrapper = 0
+	wkt.merge_from(wkt_patch)
+	assert_eq(wkt.struct_field, {"a": 1.0, "b": 3.0}, "Struct fields merge by key")
+	assert_eq(wkt.int32_wrapper, 5, "A wrapped default value does not overwrite")
+
+	assert_eq(state.merge_from(null), ERR_INVALID_PARAMETER, "Merging null fails")
+	assert_eq(state.merge_from_byte_array(PackedByteArray([0xFF])), ERR_PARSE_ERROR, "Invalid bytes fail")